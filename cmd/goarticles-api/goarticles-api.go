@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
+	"strings"
+
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
 
 	"os"
 
@@ -16,6 +21,7 @@ func main() {
 	log.SetOutput(os.Stdout)
 
 	configFile := flag.String("configfile", "", "yaml configuration file (optional)")
+	createAdmin := flag.String("create-admin", "", "create an admin user with this username and exit, the password is read from GOA_ADMIN_PASSWORD or prompted for")
 	flag.Parse()
 
 	log.Debug("Loading configuration")
@@ -29,21 +35,77 @@ func main() {
 		}
 	}
 
-	loglevel := setLogLevel(config.Server.LogLevel)
+	formatter, err := setLogFormat(config.Server.LogFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.SetFormatter(formatter)
+
+	loglevel, err := setLogLevel(config.Server.LogLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	log.Debugf("LogLevel: %v", loglevel)
 	log.SetLevel(loglevel)
 
+	if *createAdmin != "" {
+		user, err := api.CreateAdmin(config, *createAdmin, readAdminPassword())
+		if err != nil {
+			log.Fatalf("unable to create admin user: %v", err)
+		}
+
+		log.Infof("created admin user %v", user.Username)
+		os.Exit(0)
+	}
+
 	api.NewServer(config)
 
 }
 
-func setLogLevel(logLevel string) log.Level {
-	switch logLevel {
-	case "debug":
-		return log.DebugLevel
-	case "error":
-		return log.ErrorLevel
+func setLogLevel(logLevel string) (log.Level, error) {
+	if logLevel == "" {
+		return log.InfoLevel, nil
+	}
+
+	level, err := log.ParseLevel(logLevel)
+	if err != nil {
+		return log.InfoLevel, fmt.Errorf("unknown log level %v, expected panic, fatal, error, warn, info, debug or trace", logLevel)
+	}
+
+	return level, nil
+}
+
+//setLogFormat returns the formatter for logFormat, json is kept as the default so existing deployments log as before
+func setLogFormat(logFormat string) (log.Formatter, error) {
+	switch strings.ToLower(logFormat) {
+	case "", "json":
+		return &log.JSONFormatter{}, nil
+	case "text":
+		return &log.TextFormatter{FullTimestamp: true}, nil
 	default:
-		return log.InfoLevel
+		return nil, fmt.Errorf("unknown log format %v, expected json or text", logFormat)
+	}
+}
+
+func readAdminPassword() string {
+	if password, exists := os.LookupEnv("GOA_ADMIN_PASSWORD"); exists {
+		return password
 	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	defer fmt.Fprintln(os.Stderr)
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			log.Fatalf("unable to read password: %v", err)
+		}
+
+		return string(password)
+	}
+
+	password, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimRight(password, "\r\n")
 }
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/internal/utils"
+	"github.com/evcraddock/goarticles/pkg/apikeys"
+	"github.com/evcraddock/goarticles/pkg/repos"
+)
+
+//lastUsedInterval how often the last used time of a key is saved
+const lastUsedInterval = time.Minute
+
+//APIKeyController model
+type APIKeyController struct {
+	repository repos.APIKeyRepository
+}
+
+//CreateAPIKeyController creates controller and sets routes
+func CreateAPIKeyController(dbaddress, dbport, dbname string) APIKeyController {
+	log.Debugf("CreateAPIKeyController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := APIKeyController{repository: *repos.CreateAPIKeyRepository(dbserver, dbname)}
+
+	log.Debugf("CreateAPIKeyController finished")
+	return controller
+}
+
+//GetAPIKeyRoutes return list of routes for api keys
+func (c *APIKeyController) GetAPIKeyRoutes() []Route {
+	return []Route{
+		{"GET", "/api/apikeys", services.ScopeAdmin, c.GetAll},
+		{"POST", "/api/apikeys", services.ScopeAdmin, c.Add},
+		{"DELETE", "/api/apikeys/{id}", services.ScopeAdmin, c.Revoke},
+	}
+}
+
+//GetAll returns all api keys without their hashes
+func (c *APIKeyController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	results, err := c.repository.GetAPIKeys()
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(results)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("GetAll api keys")
+	return nil
+}
+
+//Add creates a new api key, the key is returned only in this response
+func (c *APIKeyController) Add(w http.ResponseWriter, r *http.Request) error {
+	key := apikeys.APIKey{}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+	if err != nil {
+		return services.NewError(err, "body is invalid", "FormatError", false)
+	}
+
+	defer r.Body.Close()
+	if err := services.NewError(
+		json.Unmarshal(body, &key),
+		"error loading data while saving api key",
+		"FormatError",
+		false); err != nil {
+		return err
+	}
+
+	if err := key.ValidateAPIKey(); err != nil {
+		return services.NewError(err, err.Error(), "ValidationError", false)
+	}
+
+	for _, scope := range key.Scopes {
+		if !utils.Contains(services.Scopes, scope) {
+			err := fmt.Errorf("unknown scope: %v", scope)
+			return services.NewError(err, err.Error(), "ValidationError", false)
+		}
+	}
+
+	plain, hash, err := apikeys.Generate()
+	if err != nil {
+		return services.NewError(err, "unable to generate api key", "InternalError", true)
+	}
+
+	key.Hash = hash
+	key.Prefix = apikeys.KeyPrefix(plain)
+	key.LastUsedAt = time.Time{}
+	key.RevokedAt = time.Time{}
+
+	newKey, err := c.repository.AddAPIKey(key)
+	if err != nil {
+		return err
+	}
+
+	newKey.Key = plain
+
+	data, _ := json.Marshal(newKey)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+
+	log.Infof("Created api key %v (%v) by %v", newKey.ID.Hex(), newKey.Name, services.TokenSubject(r))
+	return nil
+}
+
+//Revoke revokes requested api key
+func (c *APIKeyController) Revoke(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	key, err := c.repository.RevokeAPIKey(id)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(key)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Infof("Revoked api key %v by %v", id, services.TokenSubject(r))
+	return nil
+}
+
+//ValidateAPIKey returns the principal of an active api key
+func (c *APIKeyController) ValidateAPIKey(value string) (*services.Principal, error) {
+	if !apikeys.IsKey(value) {
+		return nil, fmt.Errorf("malformed api key")
+	}
+
+	key, err := c.repository.GetAPIKeyByHash(apikeys.Hash(value))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !key.IsActive(now) {
+		return nil, fmt.Errorf("api key %v is revoked or expired", key.ID.Hex())
+	}
+
+	if now.Sub(key.LastUsedAt) > lastUsedInterval {
+		if err := c.repository.UpdateLastUsed(key.ID, now); err != nil {
+			log.Errorf("unable to update last used time of api key %v: %v", key.ID.Hex(), err)
+		}
+	}
+
+	return &services.Principal{
+		Subject:  "apikey:" + key.ID.Hex(),
+		ClientID: key.ID.Hex(),
+		Scopes:   key.Scopes,
+	}, nil
+}
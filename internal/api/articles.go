@@ -1,33 +1,56 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	cache "github.com/patrickmn/go-cache"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/mgo.v2/bson"
 
 	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/internal/utils"
 	"github.com/evcraddock/goarticles/pkg/articles"
+	"github.com/evcraddock/goarticles/pkg/markdown"
 	"github.com/evcraddock/goarticles/pkg/repos"
+	"github.com/evcraddock/goarticles/pkg/webhooks"
 )
 
+const defaultRelatedLimit = 5
+
+//changes saved within syncDelay are left for the next sync so writes still in flight are not skipped
+const syncDelay = time.Second
+
+//query parameters that change the response rather than filter articles
+var reservedParameters = []string{"format", "mode"}
+
 //ArticleController model
 type ArticleController struct {
 	repository repos.ArticleRepository
+	series     repos.SeriesRepository
+	comments   repos.CommentRepository
+	related    *cache.Cache
+	events     EventPublisher
 }
 
 //CreateArticleController creates controller and sets routes
-func CreateArticleController(dbaddress, dbport, dbname string) ArticleController {
+func CreateArticleController(dbaddress, dbport, dbname string, events EventPublisher) ArticleController {
 	log.Debugf("CreateArticleController started")
 	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
 	repository := repos.CreateArticleRepository(dbserver, dbname)
-	controller := ArticleController{repository: *repository}
+	controller := ArticleController{
+		repository: *repository,
+		series:     *repos.CreateSeriesRepository(dbserver, dbname),
+		comments:   *repos.CreateCommentRepository(dbserver, dbname),
+		related:    cache.New(10*time.Minute, 20*time.Minute),
+		events:     events,
+	}
 
 	log.Debugf("CreateArticleController finished")
 	return controller
@@ -36,11 +59,15 @@ func CreateArticleController(dbaddress, dbport, dbname string) ArticleController
 //GetArticleRoutes return list of routes for articles
 func (c *ArticleController) GetArticleRoutes() []Route {
 	return []Route{
-		{"GET", "/api/articles", false, c.GetAll},
-		{"GET", "/api/articles/{id}", false, c.GetByID},
-		{"POST", "/api/articles", true, c.Add},
-		{"PUT", "/api/articles/{id}", true, c.Update},
-		{"DELETE", "/api/articles/{id}", true, c.Delete},
+		{"GET", "/api/articles", Public, c.GetAll},
+		{"GET", "/api/articles/slug/{slug}", Public, c.GetBySlug},
+		{"GET", "/api/articles/{id}", Public, c.GetByID},
+		{"GET", "/api/articles/{id}/translations", Public, c.GetTranslations},
+		{"GET", "/api/articles/{id}/related", Public, c.GetRelated},
+		{"GET", "/api/changes", Public, c.GetChanges},
+		{"POST", "/api/articles", services.ScopeWriteArticles, c.Add},
+		{"PUT", "/api/articles/{id}", services.ScopeWriteArticles, c.Update},
+		{"DELETE", "/api/articles/{id}", services.ScopeDeleteArticles, c.Delete},
 	}
 }
 
@@ -52,29 +79,176 @@ func (c *ArticleController) GetByID(w http.ResponseWriter, r *http.Request) erro
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	article, err := c.repository.GetArticle(id)
+	article, err := c.repository.GetArticle(r.Context(), id)
 	if err != nil {
 		return err
 	}
 
-	data, _ := json.Marshal(article)
+	if err := checkCanRead(r, article); err != nil {
+		return err
+	}
+
+	if err := c.writeArticle(w, r, article); err != nil {
+		return err
+	}
+
+	log.Info("Get article by id")
+
+	return nil
+}
 
+//GetBySlug returns the article with the url slug in the language that best matches Accept-Language
+func (c *ArticleController) GetBySlug(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	candidates, err := c.repository.GetArticles(r.Context(), readableQuery(r, bson.M{"url": slug}))
+	if err != nil {
+		return err
+	}
+
+	if len(*candidates) == 0 {
+		err := fmt.Errorf("article does not exist: %v", slug)
+		return services.NewError(err, "article doesn't exist", "NotFound", false)
+	}
+
+	groups := make([]string, 0)
+	for _, candidate := range *candidates {
+		if candidate.TranslationGroup != "" {
+			groups = append(groups, candidate.TranslationGroup)
+		}
+	}
+
+	if len(groups) > 0 {
+		translations, err := c.repository.GetArticles(r.Context(), readableQuery(r, bson.M{
+			"translationgroup": bson.M{"$in": groups},
+			"_id":              bson.M{"$nin": articleIDs(*candidates)},
+		}))
+		if err != nil {
+			return err
+		}
+
+		*candidates = append(*candidates, *translations...)
+	}
+
+	acceptLanguage := r.Header.Get("Accept-Language")
+	if lang := r.URL.Query().Get("language"); lang != "" {
+		acceptLanguage = lang
+	}
+
+	w.Header().Set("Vary", "Accept-Language")
+	if err := c.writeArticle(w, r, articles.SelectTranslation(*candidates, acceptLanguage)); err != nil {
+		return err
+	}
+
+	log.Info("Get article by slug")
+
+	return nil
+}
+
+//GetTranslations returns the other articles in the translation group of the requested article
+func (c *ArticleController) GetTranslations(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	article, err := c.repository.GetArticle(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkCanRead(r, article); err != nil {
+		return err
+	}
+
+	translations := &articles.Articles{}
+	if article.TranslationGroup != "" {
+		translations, err = c.repository.GetArticles(r.Context(), readableQuery(r, bson.M{
+			"translationgroup": article.TranslationGroup,
+			"_id":              bson.M{"$ne": article.ID},
+		}))
+		if err != nil {
+			return err
+		}
+	}
+
+	data, _ := json.Marshal(translations)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(http.StatusOK)
 	w.Write(data)
-	log.Info("Get article by id")
 
+	log.Info("Get article translations")
+	return nil
+}
+
+//GetRelated returns the published articles most similar to the requested article
+func (c *ArticleController) GetRelated(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	limit := defaultRelatedLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return services.NewError(fmt.Errorf("invalid limit: %v", value), "limit must be a positive number", "ValidationError", false)
+		}
+
+		limit = n
+	}
+
+	article, err := c.repository.GetArticle(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkCanRead(r, article); err != nil {
+		return err
+	}
+
+	cacheKey := fmt.Sprintf("%v:%v", id, limit)
+	related, found := c.related.Get(cacheKey)
+	if !found {
+		candidates, err := c.repository.GetArticles(r.Context(), publishedQuery())
+		if err != nil {
+			return err
+		}
+
+		related = articles.RelatedArticles(*article, *candidates, limit)
+		c.related.Set(cacheKey, related, cache.DefaultExpiration)
+	}
+
+	data, _ := json.Marshal(related)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get related articles")
 	return nil
 }
 
 //GetAll returns all queried articles
 func (c *ArticleController) GetAll(w http.ResponseWriter, r *http.Request) error {
 	vars := r.URL.Query()
-	query := c.createQuery(vars)
-	articles, err := c.repository.GetArticles(query)
+	query := readableQuery(r, createArticleQuery(vars))
+	articles, err := c.repository.GetArticles(r.Context(), query)
 	if err != nil {
 		return err
 	}
 
+	if err := c.setCommentCounts(*articles); err != nil {
+		return err
+	}
+
+	for i := range *articles {
+		if err := c.renderContent(vars.Get("format"), &(*articles)[i]); err != nil {
+			return err
+		}
+	}
+
 	data, _ := json.Marshal(articles)
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -85,55 +259,82 @@ func (c *ArticleController) GetAll(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
-//Add adds new article
-func (c *ArticleController) Add(w http.ResponseWriter, r *http.Request) error {
-	var article articles.Article
-
-	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
-	if err != nil {
-		return services.NewError(err, "body is invalid", "FormatError", false)
+//GetChanges returns articles saved and deleted since the sync token in the since parameter
+func (c *ArticleController) GetChanges(w http.ResponseWriter, r *http.Request) error {
+	var since time.Time
+	if token := r.URL.Query().Get("since"); token != "" {
+		var err error
+		if since, err = articles.ParseSyncToken(token); err != nil {
+			return services.NewError(err, "since must be a token returned by a previous request", "ValidationError", false)
+		}
 	}
 
-	defer r.Body.Close()
-	if err := services.NewError(
-		json.Unmarshal(body, &article),
-		"error loading data while updating article",
-		"FormatError",
-		false); err != nil {
-		return err
+	until := time.Now().UTC().Add(-syncDelay).Truncate(time.Millisecond)
+	if since.After(until) {
+		until = since
 	}
 
-	newArticle, err := c.repository.AddArticle(article)
+	changes, err := c.repository.GetChanges(r.Context(), since, until)
 	if err != nil {
 		return err
 	}
 
-	data, _ := json.Marshal(newArticle)
+	changes.Token = articles.NewSyncToken(until)
+	if !services.RequestHasScope(r, services.ScopeReadDrafts) {
+		published := make(articles.Articles, 0, len(changes.Updated))
+		for _, article := range changes.Updated {
+			if article.IsPublished() {
+				published = append(published, article)
+			}
+		}
+
+		changes.Updated = published
+	}
 
+	data, _ := json.Marshal(changes)
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusCreated)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
 	w.Write(data)
 
+	log.Info("Get article changes")
 	return nil
 }
 
+//Add adds new article
+func (c *ArticleController) Add(w http.ResponseWriter, r *http.Request) error {
+	article, err := readArticle(r)
+	if err != nil {
+		return err
+	}
+
+	if err := checkArticleOwner(r, article); err != nil {
+		return err
+	}
+
+	article.SetReadingMetadata()
+	newArticle, err := c.repository.AddArticle(r.Context(), *article)
+	if err != nil {
+		return err
+	}
+
+	c.related.Flush()
+	recordArticleChange(r, nil, newArticle)
+	c.events.Publish(webhooks.ArticleCreated, newArticle)
+	if newArticle.IsPublished() {
+		c.events.Publish(webhooks.ArticlePublished, newArticle)
+	}
+
+	return writeSavedArticle(w, r, http.StatusCreated, newArticle)
+}
+
 //Update updates existing article
 func (c *ArticleController) Update(w http.ResponseWriter, r *http.Request) error {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var article articles.Article
-	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+	article, err := readArticle(r)
 	if err != nil {
-		return services.NewError(err, "body is invalid", "FormatError", false)
-	}
-
-	defer r.Body.Close()
-	if err := services.NewError(
-		json.Unmarshal(body, &article),
-		"error loading data while updating article",
-		"FormatError",
-		false); err != nil {
 		return err
 	}
 
@@ -143,18 +344,32 @@ func (c *ArticleController) Update(w http.ResponseWriter, r *http.Request) error
 		return services.NewError(err, "article id does not match url parameter", "ValidationError", false)
 	}
 
-	updatedArticle, err := c.repository.UpdateArticle(article)
+	previous, err := c.repository.GetArticle(r.Context(), id)
 	if err != nil {
 		return err
 	}
 
-	data, _ := json.Marshal(updatedArticle)
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	for _, owned := range []*articles.Article{previous, article} {
+		if err := checkArticleOwner(r, owned); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	article.SetReadingMetadata()
+	updatedArticle, err := c.repository.UpdateArticle(r.Context(), *article)
+	if err != nil {
+		return err
+	}
+
+	c.related.Flush()
+	recordArticleChange(r, previous, updatedArticle)
+	c.events.Publish(webhooks.ArticleUpdated, updatedArticle)
+	if updatedArticle.IsPublished() && !previous.IsPublished() {
+		c.events.Publish(webhooks.ArticlePublished, updatedArticle)
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	return writeSavedArticle(w, r, http.StatusOK, updatedArticle)
 }
 
 //Delete deletes requested article
@@ -162,11 +377,27 @@ func (c *ArticleController) Delete(w http.ResponseWriter, r *http.Request) error
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := c.repository.DeleteArticle(id)
+	article, err := c.repository.GetArticle(r.Context(), id)
 	if err != nil {
 		return err
 	}
 
+	if err := checkArticleOwner(r, article); err != nil {
+		return err
+	}
+
+	if err := c.repository.DeleteArticle(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := c.comments.DeleteArticleComments(id); err != nil {
+		return err
+	}
+
+	c.related.Flush()
+	recordArticleChange(r, article, nil)
+	c.events.Publish(webhooks.ArticleDeleted, article)
+
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(http.StatusOK)
@@ -174,15 +405,190 @@ func (c *ArticleController) Delete(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
-func (c *ArticleController) createQuery(vars url.Values) bson.M {
+func (c *ArticleController) writeArticle(w http.ResponseWriter, r *http.Request, article *articles.Article) error {
+	w.Header().Add("Vary", "Accept")
+	if acceptsMarkdown(r) {
+		return writeMarkdown(w, http.StatusOK, article)
+	}
+
+	var err error
+	if article.Navigation, err = c.getNavigation(r.Context(), article); err != nil {
+		return err
+	}
+
+	counts, err := c.comments.CountApprovedComments([]string{article.ID.Hex()})
+	if err != nil {
+		return err
+	}
+
+	article.CommentCount = counts[article.ID.Hex()]
+
+	if err := c.renderContent(r.URL.Query().Get("format"), article); err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(article)
+
+	if article.Language != "" {
+		w.Header().Set("Content-Language", article.Language)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	return nil
+}
+
+func (c *ArticleController) getNavigation(ctx context.Context, article *articles.Article) (*articles.Navigation, error) {
+	previous, next, err := c.repository.GetAdjacentArticles(ctx, *article)
+	if err != nil {
+		return nil, err
+	}
+
+	navigation := &articles.Navigation{
+		Previous: articles.NewArticleLink(previous),
+		Next:     articles.NewArticleLink(next),
+	}
+
+	if article.Series == "" {
+		return navigation, nil
+	}
+
+	series, err := c.series.GetSeries(article.Series)
+	if err != nil {
+		log.Debugf("unable to load series %v: %v", article.Series, err)
+		return navigation, nil
+	}
+
+	position := series.IndexOf(article.ID.Hex())
+	if position < 0 {
+		return navigation, nil
+	}
+
+	navigation.Series = &articles.SeriesNavigation{
+		Slug:     series.Slug,
+		Title:    series.Title,
+		Position: position + 1,
+		Total:    len(series.Articles),
+	}
+
+	//unpublished articles in the series are skipped
+	for i := position - 1; i >= 0 && navigation.Series.Previous == nil; i-- {
+		navigation.Series.Previous = c.getArticleLink(ctx, series.Articles[i])
+	}
+
+	for i := position + 1; i < len(series.Articles) && navigation.Series.Next == nil; i++ {
+		navigation.Series.Next = c.getArticleLink(ctx, series.Articles[i])
+	}
+
+	return navigation, nil
+}
+
+func (c *ArticleController) renderContent(format string, article *articles.Article) error {
+	switch format {
+	case "", "json":
+		return nil
+	case "html":
+		html, err := markdown.Render(article.Content)
+		if err != nil {
+			return services.NewError(err, "unable to render article content", "RenderError", false)
+		}
+
+		article.HTML = html
+		return nil
+	default:
+		err := fmt.Errorf("invalid format: %v", format)
+		return services.NewError(err, "format must be json or html", "ValidationError", false)
+	}
+}
+
+func (c *ArticleController) setCommentCounts(results articles.Articles) error {
+	ids := make([]string, len(results))
+	for i, article := range results {
+		ids[i] = article.ID.Hex()
+	}
+
+	counts, err := c.comments.CountApprovedComments(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		results[i].CommentCount = counts[results[i].ID.Hex()]
+	}
+
+	return nil
+}
+
+//getArticleLink returns a link to the article with id, nil when it is missing or not published
+func (c *ArticleController) getArticleLink(ctx context.Context, id string) *articles.ArticleLink {
+	article, err := c.repository.GetArticle(ctx, id)
+	if err != nil {
+		log.Debugf("unable to load article %v: %v", id, err)
+		return nil
+	}
+
+	if !article.IsPublished() {
+		return nil
+	}
+
+	return articles.NewArticleLink(article)
+}
+
+//checkArticleOwner rejects changes by author role users to articles they did not write
+func checkArticleOwner(r *http.Request, article *articles.Article) error {
+	principal := services.RequestPrincipal(r)
+	if principal == nil || !principal.OwnArticlesOnly() {
+		return nil
+	}
+
+	if article.Author != principal.Subject {
+		err := fmt.Errorf("%v is not the author of article %v", principal.Subject, article.ID.Hex())
+		return services.NewError(err, "authors can only change their own articles", "Forbidden", false)
+	}
+
+	return nil
+}
+
+//readableQuery limits query to published articles unless the request was sent with the read:drafts scope
+func readableQuery(r *http.Request, query bson.M) bson.M {
+	if services.RequestHasScope(r, services.ScopeReadDrafts) {
+		return query
+	}
+
+	return bson.M{"$and": []bson.M{query, publishedQuery()}}
+}
+
+//checkCanRead hides unpublished articles from requests without the read:drafts scope
+func checkCanRead(r *http.Request, article *articles.Article) error {
+	if article.IsPublished() || services.RequestHasScope(r, services.ScopeReadDrafts) {
+		return nil
+	}
+
+	err := fmt.Errorf("article %v is not published", article.ID.Hex())
+	return services.NewError(err, "article doesn't exist", "NotFound", false)
+}
+
+//publishedQuery matches articles with a publish date that has already passed
+func publishedQuery() bson.M {
+	return bson.M{"publishdate": bson.M{"$gt": time.Time{}, "$lte": time.Now()}}
+}
+
+func createArticleQuery(vars url.Values) bson.M {
 	query := make(bson.M)
 
 	for k, v := range vars {
+		if utils.Contains(reservedParameters, k) {
+			continue
+		}
+
 		switch k {
 		case "categories":
 			query[k] = bson.M{"$in": v}
 		case "tags":
 			query[k] = bson.M{"$in": v}
+		case "language":
+			query[k] = bson.M{"$in": v}
 		default:
 			query[k] = v[0]
 		}
@@ -191,3 +597,12 @@ func (c *ArticleController) createQuery(vars url.Values) bson.M {
 
 	return query
 }
+
+func articleIDs(results articles.Articles) []bson.ObjectId {
+	ids := make([]bson.ObjectId, len(results))
+	for i, article := range results {
+		ids[i] = article.ID
+	}
+
+	return ids
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/articles"
+)
+
+type principalKeys map[string]*services.Principal
+
+func (k principalKeys) ValidateAPIKey(key string) (*services.Principal, error) {
+	return k[key], nil
+}
+
+//serveAs serves handler with the principal authorized for the request, anonymously when principal is nil
+func serveAs(principal *services.Principal, handler http.HandlerFunc) {
+	r := httptest.NewRequest("PUT", "/api/articles/1", nil)
+	if principal == nil {
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		return
+	}
+
+	auth := services.NewAuthorization(nil, "", "")
+	auth.UseAPIKeys(principalKeys{"test": principal})
+
+	r.Header.Set("Authorization", "ApiKey test")
+	auth.Authorize(handler).ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestCheckArticleOwner(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *services.Principal
+		author    string
+		allowed   bool
+	}{
+		{"anonymous", nil, "author", true},
+		{"editor", &services.Principal{Subject: "editor", Roles: []string{services.RoleEditor}}, "author", true},
+		{"admin", &services.Principal{Subject: "admin", Roles: []string{services.RoleAdmin}}, "author", true},
+		{"own article", &services.Principal{Subject: "author", Roles: []string{services.RoleAuthor}}, "author", true},
+		{"other article", &services.Principal{Subject: "other", Roles: []string{services.RoleAuthor}}, "author", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			served := false
+			serveAs(test.principal, func(w http.ResponseWriter, r *http.Request) {
+				served = true
+
+				err := checkArticleOwner(r, &articles.Article{Author: test.author})
+				if allowed := err == nil; allowed != test.allowed {
+					t.Fatalf("expected allowed %v, got %v", test.allowed, err)
+				}
+			})
+
+			if !served {
+				t.Fatal("request was not authorized")
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/articles"
+	"github.com/evcraddock/goarticles/pkg/audit"
+	"github.com/evcraddock/goarticles/pkg/repos"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type auditContextKey struct{}
+
+//AuditController records authenticated writes and returns the audit log
+type AuditController struct {
+	repository repos.AuditRepository
+}
+
+//CreateAuditController creates controller and sets routes
+func CreateAuditController(dbaddress, dbport, dbname string) AuditController {
+	log.Debugf("CreateAuditController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := AuditController{repository: *repos.CreateAuditRepository(dbserver, dbname)}
+
+	log.Debugf("CreateAuditController finished")
+	return controller
+}
+
+//GetAuditRoutes return list of routes for the audit log
+func (c *AuditController) GetAuditRoutes() []Route {
+	return []Route{
+		{"GET", "/api/audit", services.ScopeAdmin, c.GetEntries},
+	}
+}
+
+//Audit wraps the handler of an authenticated route and records an entry for every request
+func (c *AuditController) Audit(route Route, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entry := &audit.Entry{
+			Subject:   services.TokenSubject(r),
+			ClientID:  services.TokenClientID(r),
+			Method:    r.Method,
+			Route:     route.Path,
+			Path:      r.URL.Path,
+			RequestID: services.RequestID(r),
+			CreatedAt: time.Now().UTC(),
+		}
+
+		if strings.HasPrefix(route.Path, "/api/articles/{id}") {
+			entry.ArticleID = mux.Vars(r)["id"]
+		}
+
+		recorder := newResponseRecorder(w)
+		handler.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), auditContextKey{}, entry)))
+
+		entry.Status = recorder.status
+		if err := c.repository.AddEntry(*entry); err != nil {
+			services.RequestLog(r).Errorf("unable to record audit entry for %v %v: %v", entry.Method, entry.Path, err)
+		}
+	})
+}
+
+//GetEntries returns audit entries filtered by the user, article, from and to parameters
+func (c *AuditController) GetEntries(w http.ResponseWriter, r *http.Request) error {
+	vars := r.URL.Query()
+	query := bson.M{}
+
+	if user := vars.Get("user"); user != "" {
+		query["subject"] = user
+	}
+
+	if article := vars.Get("article"); article != "" {
+		query["articleid"] = article
+	}
+
+	createdAt := bson.M{}
+	for parameter, operator := range map[string]string{"from": "$gte", "to": "$lte"} {
+		value := vars.Get(parameter)
+		if value == "" {
+			continue
+		}
+
+		t, err := parseAuditTime(value)
+		if err != nil {
+			return services.NewError(err, parameter+" must be a date or RFC 3339 time", "ValidationError", false)
+		}
+
+		createdAt[operator] = t
+	}
+
+	if len(createdAt) > 0 {
+		query["createdat"] = createdAt
+	}
+
+	limit := defaultAuditLimit
+	if value := vars.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxAuditLimit {
+			err = fmt.Errorf("invalid limit: %v", value)
+			return services.NewError(err, "limit must be a number between 1 and 1000", "ValidationError", false)
+		}
+
+		limit = parsed
+	}
+
+	entries, err := c.repository.GetEntries(query, limit)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(entries)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get audit entries")
+	return nil
+}
+
+//recordArticleChange adds the article id and the hashes of the article before and after a change to the audit entry
+func recordArticleChange(r *http.Request, before, after *articles.Article) {
+	entry, ok := r.Context().Value(auditContextKey{}).(*audit.Entry)
+	if !ok {
+		return
+	}
+
+	if before != nil {
+		entry.ArticleID = before.ID.Hex()
+		entry.Before = audit.Hash(before)
+	}
+
+	if after != nil {
+		entry.ArticleID = after.ID.Hex()
+		entry.After = audit.Hash(after)
+	}
+}
+
+func parseAuditTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/evcraddock/goarticles/internal/services"
+)
+
+const forwardedForHeader = "X-Forwarded-For"
+
+//TrustedProxies proxies whose X-Forwarded-For header is used to find the client's address
+type TrustedProxies []*net.IPNet
+
+//ParseTrustedProxies parses a list of ip addresses and cidr ranges
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("%v is not an ip address or cidr range", value)
+			}
+
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+
+			value = fmt.Sprintf("%v/%v", value, bits)
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v is not an ip address or cidr range", value)
+		}
+
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+//Contains checks if ip belongs to a trusted proxy
+func (p TrustedProxies) Contains(ip net.IP) bool {
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//ClientIP returns the address of the client that sent the request, X-Forwarded-For is only read when the request
+//came from a trusted proxy and then the right-most address that is not a trusted proxy is used
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	remote := remoteHost(r)
+
+	ip := net.ParseIP(remote)
+	if ip == nil || !p.Contains(ip) {
+		return remote
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(forwarded[i])
+		if address == "" {
+			continue
+		}
+
+		hop := net.ParseIP(address)
+		if hop == nil {
+			break
+		}
+
+		remote = hop.String()
+		if !p.Contains(hop) {
+			break
+		}
+	}
+
+	return remote
+}
+
+//clientAddress returns the client address resolved for the request's access log, the connection's address when the
+//request was not logged
+func clientAddress(r *http.Request) string {
+	if info := services.GetRequestInfo(r); info != nil && info.IP != "" {
+		return info.IP
+	}
+
+	return remoteHost(r)
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
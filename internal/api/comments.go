@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	cache "github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/articles"
+	"github.com/evcraddock/goarticles/pkg/repos"
+)
+
+const (
+	maxCommentsPerWindow = 5
+	commentRateWindow    = 10 * time.Minute
+)
+
+//CommentController model
+type CommentController struct {
+	repository repos.CommentRepository
+	articles   repos.ArticleRepository
+	limiter    *cache.Cache
+}
+
+//CreateCommentController creates controller and sets routes
+func CreateCommentController(dbaddress, dbport, dbname string) CommentController {
+	log.Debugf("CreateCommentController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := CommentController{
+		repository: *repos.CreateCommentRepository(dbserver, dbname),
+		articles:   *repos.CreateArticleRepository(dbserver, dbname),
+		limiter:    cache.New(commentRateWindow, 2*commentRateWindow),
+	}
+
+	log.Debugf("CreateCommentController finished")
+	return controller
+}
+
+//GetCommentRoutes return list of routes for comments
+func (c *CommentController) GetCommentRoutes() []Route {
+	return []Route{
+		{"GET", "/api/articles/{id}/comments", Public, c.GetByArticle},
+		{"POST", "/api/articles/{id}/comments", Public, c.Add},
+		{"GET", "/api/comments", services.ScopeModerateComments, c.GetModerationQueue},
+		{"PUT", "/api/comments/{commentId}/status", services.ScopeModerateComments, c.UpdateStatus},
+		{"DELETE", "/api/comments/{commentId}", services.ScopeModerateComments, c.Delete},
+	}
+}
+
+//GetByArticle returns approved comments for an article as threads
+func (c *CommentController) GetByArticle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	comments, err := c.repository.GetComments(map[string]interface{}{
+		"articleid": id,
+		"status":    articles.CommentApproved,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(comments.Thread())
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get comments by article")
+	return nil
+}
+
+//Add adds a new comment, anonymous comments are held for moderation, comments sent with a valid token or api key
+//are approved and invalid credentials are treated as anonymous
+func (c *CommentController) Add(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	articleID := vars["id"]
+
+	ip := clientAddress(r)
+	if !c.allowComment(ip) {
+		err := fmt.Errorf("too many comments from %v", ip)
+		return services.NewError(err, "too many comments, try again later", "RateLimited", false)
+	}
+
+	var comment articles.Comment
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+	if err != nil {
+		return services.NewError(err, "body is invalid", "FormatError", false)
+	}
+
+	defer r.Body.Close()
+	if err := services.NewError(
+		json.Unmarshal(body, &comment),
+		"error loading data while adding comment",
+		"FormatError",
+		false); err != nil {
+		return err
+	}
+
+	if err := services.NewError(comment.ValidateComment(), "comment is invalid", "ValidationError", false); err != nil {
+		return err
+	}
+
+	if _, err := c.articles.ArticleExists(r.Context(), articleID); err != nil {
+		return err
+	}
+
+	if comment.ParentID != "" {
+		parent, err := c.repository.GetComment(comment.ParentID)
+		if err != nil {
+			return err
+		}
+
+		if parent.ArticleID != articleID {
+			err := fmt.Errorf("parent comment %v belongs to article %v", parent.ID.Hex(), parent.ArticleID)
+			return services.NewError(err, "parent comment does not belong to article", "ValidationError", false)
+		}
+	}
+
+	comment.ID = ""
+	comment.ArticleID = articleID
+	comment.IPAddress = ip
+	comment.CreatedAt = time.Now().UTC()
+	comment.Subject = ""
+	comment.Verified = false
+	comment.Status = articles.CommentPending
+	if principal := services.RequestPrincipal(r); principal != nil {
+		comment.Subject = principal.Subject
+		comment.Verified = true
+		comment.Status = articles.CommentApproved
+	}
+
+	newComment, err := c.repository.AddComment(comment)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(newComment)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+
+	return nil
+}
+
+//GetModerationQueue returns comments by moderation status, pending by default
+func (c *CommentController) GetModerationQueue(w http.ResponseWriter, r *http.Request) error {
+	vars := r.URL.Query()
+
+	status := vars.Get("status")
+	if status == "" {
+		status = articles.CommentPending
+	}
+
+	if !articles.IsValidCommentStatus(status) {
+		err := fmt.Errorf("invalid status: %v", status)
+		return services.NewError(err, "status must be pending, approved or spam", "ValidationError", false)
+	}
+
+	query := map[string]interface{}{"status": status}
+	if articleID := vars.Get("articleId"); articleID != "" {
+		query["articleid"] = articleID
+	}
+
+	comments, err := c.repository.GetComments(query)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(comments)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get comment moderation queue")
+	return nil
+}
+
+//UpdateStatus sets the moderation status of a comment
+func (c *CommentController) UpdateStatus(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["commentId"]
+
+	var update struct {
+		Status string `json:"status"`
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+	if err != nil {
+		return services.NewError(err, "body is invalid", "FormatError", false)
+	}
+
+	defer r.Body.Close()
+	if err := services.NewError(
+		json.Unmarshal(body, &update),
+		"error loading data while updating comment",
+		"FormatError",
+		false); err != nil {
+		return err
+	}
+
+	if !articles.IsValidCommentStatus(update.Status) {
+		err := fmt.Errorf("invalid status: %v", update.Status)
+		return services.NewError(err, "status must be pending, approved or spam", "ValidationError", false)
+	}
+
+	if err := c.repository.UpdateCommentStatus(id, update.Status); err != nil {
+		return err
+	}
+
+	log.Infof("Comment %v moderated as %v by %v", id, update.Status, services.TokenSubject(r))
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+//Delete deletes requested comment and its replies
+func (c *CommentController) Delete(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["commentId"]
+
+	if err := c.repository.DeleteComment(id); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+func (c *CommentController) allowComment(ip string) bool {
+	if err := c.limiter.Add(ip, 1, cache.DefaultExpiration); err == nil {
+		return true
+	}
+
+	count, err := c.limiter.IncrementInt(ip, 1)
+	if err != nil {
+		return true
+	}
+
+	return count <= maxCommentsPerWindow
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/repos"
+	"github.com/evcraddock/goarticles/pkg/webhooks"
+)
+
+const (
+	deliveryInterval  = 15 * time.Second
+	deliveryBatchSize = 50
+	deliveryTimeout   = 10 * time.Second
+)
+
+//WebhookDispatcher stores events for subscribed webhooks and sends them in the background
+type WebhookDispatcher struct {
+	repository repos.WebhookRepository
+	client     *http.Client
+	wake       chan struct{}
+}
+
+//CreateWebhookDispatcher creates dispatcher, call Start to begin sending deliveries
+func CreateWebhookDispatcher(dbaddress, dbport, dbname string) *WebhookDispatcher {
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	return &WebhookDispatcher{
+		repository: *repos.CreateWebhookRepository(dbserver, dbname),
+		client:     &http.Client{Timeout: deliveryTimeout},
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+//Publish records a delivery for every webhook subscribed to eventType
+func (d *WebhookDispatcher) Publish(eventType string, data interface{}) {
+	subscribers, err := d.repository.GetWebhooks(map[string]interface{}{"active": true})
+	if err != nil {
+		log.Errorf("unable to load webhooks for %v: %v", eventType, err)
+		return
+	}
+
+	event := webhooks.NewEvent(eventType, data)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("unable to create %v payload: %v", eventType, err)
+		return
+	}
+
+	deliveries := webhooks.Deliveries{}
+	for _, webhook := range *subscribers {
+		if !webhook.Subscribes(eventType) {
+			continue
+		}
+
+		deliveries = append(deliveries, webhooks.Delivery{
+			WebhookID:   webhook.ID.Hex(),
+			EventID:     event.ID,
+			Event:       eventType,
+			Payload:     string(payload),
+			Status:      webhooks.DeliveryPending,
+			NextAttempt: event.CreatedAt,
+			CreatedAt:   event.CreatedAt,
+			UpdatedAt:   event.CreatedAt,
+		})
+	}
+
+	if err := d.repository.AddDeliveries(deliveries); err != nil {
+		log.Errorf("unable to save %v deliveries: %v", eventType, err)
+		return
+	}
+
+	if len(deliveries) > 0 {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+//Start sends pending deliveries until the process exits
+func (d *WebhookDispatcher) Start() {
+	go func() {
+		ticker := time.NewTicker(deliveryInterval)
+		defer ticker.Stop()
+
+		for {
+			d.sendDue()
+
+			select {
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+func (d *WebhookDispatcher) sendDue() {
+	due, err := d.repository.GetDueDeliveries(time.Now().UTC(), deliveryBatchSize)
+	if err != nil {
+		log.Errorf("unable to load webhook deliveries: %v", err)
+		return
+	}
+
+	for _, delivery := range *due {
+		d.send(&delivery)
+
+		if err := d.repository.UpdateDelivery(delivery); err != nil {
+			log.Errorf("unable to save webhook delivery %v: %v", delivery.ID.Hex(), err)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) send(delivery *webhooks.Delivery) {
+	webhook, err := d.repository.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		if e, ok := err.(services.Error); ok && e.Status() == http.StatusNotFound {
+			delivery.Abandon("webhook no longer exists")
+			return
+		}
+
+		delivery.Failed(0, err.Error())
+		return
+	}
+
+	if !webhook.Active {
+		delivery.Abandon("webhook is not active")
+		return
+	}
+
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		delivery.Failed(0, err.Error())
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "goarticles-webhook")
+	req.Header.Set("X-Goarticles-Event", delivery.Event)
+	req.Header.Set("X-Goarticles-Delivery", delivery.ID.Hex())
+	req.Header.Set("X-Goarticles-Signature", webhooks.Sign(webhook.Secret, payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		log.Debugf("webhook delivery %v failed: %v", delivery.ID.Hex(), err)
+		delivery.Failed(0, err.Error())
+		return
+	}
+
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 1048576))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		log.Debugf("webhook delivery %v failed: %v", delivery.ID.Hex(), res.Status)
+		delivery.Failed(res.StatusCode, res.Status)
+		return
+	}
+
+	delivery.Succeeded(res.StatusCode)
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/internal/utils"
+	"github.com/evcraddock/goarticles/pkg/articles"
+	"github.com/evcraddock/goarticles/pkg/webhooks"
+)
+
+const (
+	eventHistorySize  = 1000
+	subscriberBuffer  = 64
+	keepAliveInterval = 15 * time.Second
+)
+
+//EventPublisher receives article and image change events
+type EventPublisher interface {
+	Publish(eventType string, data interface{})
+}
+
+//EventBus sends every event to each publisher
+type EventBus []EventPublisher
+
+//Publish sends the event to each publisher
+func (bus EventBus) Publish(eventType string, data interface{}) {
+	for _, publisher := range bus {
+		publisher.Publish(eventType, data)
+	}
+}
+
+type streamEvent struct {
+	ID        uint64
+	Type      string
+	ArticleID string
+	Tags      []string
+	Published bool
+	Data      []byte
+}
+
+//EventStream keeps a bounded history of change events and streams them to clients as server-sent events
+type EventStream struct {
+	mutex       sync.Mutex
+	lastID      uint64
+	history     []streamEvent
+	size        int
+	subscribers map[chan streamEvent]bool
+}
+
+//CreateEventStream creates stream that keeps the last size events for clients resuming with Last-Event-ID
+func CreateEventStream(size int) *EventStream {
+	return &EventStream{
+		size:        size,
+		subscribers: make(map[chan streamEvent]bool),
+	}
+}
+
+//GetEventRoutes return list of routes for the event stream
+func (s *EventStream) GetEventRoutes() []Route {
+	return []Route{
+		{"GET", "/api/events", Public, s.Stream},
+	}
+}
+
+//Publish adds the event to the history and sends it to connected clients
+func (s *EventStream) Publish(eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Errorf("unable to create %v event: %v", eventType, err)
+		return
+	}
+
+	event := streamEvent{Type: eventType, Data: payload}
+	switch value := data.(type) {
+	case *articles.Article:
+		event.ArticleID = value.ID.Hex()
+		event.Tags = value.Tags
+		event.Published = value.IsPublished()
+	case webhooks.ImageEvent:
+		event.ArticleID = value.ArticleID
+		event.Published = value.Published
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastID++
+	event.ID = s.lastID
+
+	s.history = append(s.history, event)
+	if len(s.history) > s.size {
+		s.history = s.history[len(s.history)-s.size:]
+	}
+
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			//slow clients are disconnected and resume from the history with Last-Event-ID
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+//Stream sends change events as server-sent events, filtered by the articleId and tag query parameters, events for
+//unpublished articles are only sent to clients with the read:drafts scope
+func (s *EventStream) Stream(w http.ResponseWriter, r *http.Request) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := fmt.Errorf("response writer does not support flushing")
+		return services.NewError(err, "streaming is not supported", "NotImplemented", true)
+	}
+
+	var lastEventID uint64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return services.NewError(err, "Last-Event-ID must be an event id", "ValidationError", false)
+		}
+
+		lastEventID = parsed
+	}
+
+	vars := r.URL.Query()
+	articleIDs := vars["articleId"]
+	tags := vars["tag"]
+	drafts := services.RequestHasScope(r, services.ScopeReadDrafts)
+	include := func(event streamEvent) bool {
+		if !event.Published && !drafts {
+			return false
+		}
+
+		if len(articleIDs) > 0 && !utils.Contains(articleIDs, event.ArticleID) {
+			return false
+		}
+
+		if len(tags) == 0 {
+			return true
+		}
+
+		for _, tag := range event.Tags {
+			if utils.Contains(tags, tag) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	//events are streamed for as long as the client is connected
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Debugf("unable to clear write deadline: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+
+	subscriber, missed, expired := s.subscribe(lastEventID)
+	defer s.unsubscribe(subscriber)
+
+	if expired {
+		fmt.Fprintf(w, "event: reset\ndata: {}\n\n")
+	}
+
+	for _, event := range missed {
+		if include(event) {
+			writeStreamEvent(w, event)
+		}
+	}
+
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-keepAlive.C:
+			fmt.Fprintf(w, ": keep-alive\n\n")
+		case event, open := <-subscriber:
+			if !open {
+				return nil
+			}
+
+			if !include(event) {
+				continue
+			}
+
+			writeStreamEvent(w, event)
+		}
+
+		flusher.Flush()
+	}
+}
+
+//subscribe registers a client and returns the events it missed since lastEventID, expired is true when
+//some of those events are no longer in the history
+func (s *EventStream) subscribe(lastEventID uint64) (chan streamEvent, []streamEvent, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	subscriber := make(chan streamEvent, subscriberBuffer)
+	s.subscribers[subscriber] = true
+
+	if lastEventID == 0 {
+		return subscriber, nil, false
+	}
+
+	missed := make([]streamEvent, 0)
+	for _, event := range s.history {
+		if event.ID > lastEventID {
+			missed = append(missed, event)
+		}
+	}
+
+	expired := lastEventID > s.lastID || (len(s.history) > 0 && s.history[0].ID > lastEventID+1)
+	return subscriber, missed, expired
+}
+
+func (s *EventStream) unsubscribe(subscriber chan streamEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.subscribers[subscriber] {
+		delete(s.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, event streamEvent) {
+	fmt.Fprintf(w, "id: %v\nevent: %v\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/feeds"
+	"github.com/evcraddock/goarticles/pkg/repos"
+)
+
+const (
+	feedItems  = 20
+	feedMaxAge = 15 * time.Minute
+)
+
+//filters available for feed variants mapped to article fields
+var feedFilters = map[string]string{
+	"categories": "categories",
+	"tags":       "tags",
+	"authors":    "author",
+}
+
+//FeedController model
+type FeedController struct {
+	repository repos.ArticleRepository
+	site       feeds.Site
+}
+
+//CreateFeedController creates controller and sets routes
+func CreateFeedController(dbaddress, dbport, dbname string, site configs.SiteConfiguration) FeedController {
+	log.Debugf("CreateFeedController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := FeedController{
+		repository: *repos.CreateArticleRepository(dbserver, dbname),
+		site: feeds.Site{
+			Title:       site.Title,
+			Description: site.Description,
+			URL:         site.URL,
+			APIURL:      site.APIURL,
+		},
+	}
+
+	log.Debugf("CreateFeedController finished")
+	return controller
+}
+
+//GetFeedRoutes return list of routes for feeds
+func (c *FeedController) GetFeedRoutes() []Route {
+	return []Route{
+		{"GET", "/feeds/rss.xml", Public, c.GetRSS},
+		{"GET", "/feeds/atom.xml", Public, c.GetAtom},
+		{"GET", "/feeds/{filter:categories|tags|authors}/{value}/rss.xml", Public, c.GetRSS},
+		{"GET", "/feeds/{filter:categories|tags|authors}/{value}/atom.xml", Public, c.GetAtom},
+		{"GET", "/feeds/feed.json", Public, c.GetJSONFeed},
+	}
+}
+
+//GetRSS returns published articles as an RSS 2.0 feed
+func (c *FeedController) GetRSS(w http.ResponseWriter, r *http.Request) error {
+	feed, err := c.getFeed(r)
+	if err != nil {
+		return err
+	}
+
+	data, err := feed.RSS()
+	if err != nil {
+		return services.NewError(err, "unable to create feed", "FeedError", false)
+	}
+
+	writeCached(w, r, "application/rss+xml; charset=UTF-8", data, feed.Updated)
+	log.Info("Get rss feed")
+
+	return nil
+}
+
+//GetAtom returns published articles as an Atom feed
+func (c *FeedController) GetAtom(w http.ResponseWriter, r *http.Request) error {
+	feed, err := c.getFeed(r)
+	if err != nil {
+		return err
+	}
+
+	data, err := feed.Atom()
+	if err != nil {
+		return services.NewError(err, "unable to create feed", "FeedError", false)
+	}
+
+	writeCached(w, r, "application/atom+xml; charset=UTF-8", data, feed.Updated)
+	log.Info("Get atom feed")
+
+	return nil
+}
+
+//GetJSONFeed returns published articles matching the article list filters as a JSON Feed
+func (c *FeedController) GetJSONFeed(w http.ResponseWriter, r *http.Request) error {
+	query := createArticleQuery(r.URL.Query())
+
+	feed, err := c.createFeed(r, c.site.Title, query)
+	if err != nil {
+		return err
+	}
+
+	data, err := feed.JSON()
+	if err != nil {
+		return services.NewError(err, "unable to create feed", "FeedError", false)
+	}
+
+	writeCached(w, r, "application/feed+json; charset=UTF-8", data, feed.Updated)
+	log.Info("Get json feed")
+
+	return nil
+}
+
+func (c *FeedController) getFeed(r *http.Request) (*feeds.Feed, error) {
+	vars := mux.Vars(r)
+	title := c.site.Title
+	query := bson.M{}
+
+	if filter, found := feedFilters[vars["filter"]]; found {
+		query[filter] = vars["value"]
+		title = fmt.Sprintf("%v: %v", c.site.Title, vars["value"])
+	}
+
+	return c.createFeed(r, title, query)
+}
+
+func (c *FeedController) createFeed(r *http.Request, title string, query bson.M) (*feeds.Feed, error) {
+	for k, v := range publishedQuery() {
+		query[k] = v
+	}
+
+	fullContent := false
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", "excerpt":
+	case "full":
+		fullContent = true
+	default:
+		err := fmt.Errorf("invalid mode: %v", mode)
+		return nil, services.NewError(err, "mode must be full or excerpt", "ValidationError", false)
+	}
+
+	results, err := c.repository.GetRecentArticles(r.Context(), query, feedItems)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := feeds.NewFeed(c.site, title, c.site.APIURL+r.URL.RequestURI(), *results, fullContent)
+	if err != nil {
+		return nil, services.NewError(err, "unable to create feed", "FeedError", false)
+	}
+
+	return feed, nil
+}
+
+//writeCached writes a cacheable response, returning not modified when the client copy is current
+func writeCached(w http.ResponseWriter, r *http.Request, contentType string, data []byte, modified time.Time) {
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(data))
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%v", int(feedMaxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if match == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.IsZero() {
+		if !modified.Truncate(time.Second).After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
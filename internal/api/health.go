@@ -5,7 +5,7 @@ import "net/http"
 //GetHealthRoutes returns list of health routes
 func GetHealthRoutes() []Route {
 	return []Route{
-		{"GET", "/health", false, HealthCheck},
+		{"GET", "/health", Public, HealthCheck},
 	}
 }
 
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -10,20 +11,28 @@ import (
 	"github.com/evcraddock/goarticles/internal/services"
 	"github.com/evcraddock/goarticles/pkg/articles"
 	"github.com/evcraddock/goarticles/pkg/repos"
+	"github.com/evcraddock/goarticles/pkg/webhooks"
 )
 
 const maxMemory = 1 * 1024 * 1024
 
 //ImageController model
 type ImageController struct {
-	storage repos.StorageRepository
+	storage  repos.StorageRepository
+	articles repos.ArticleRepository
+	events   EventPublisher
 }
 
 //CreateImageController creates controller and sets routes
-func CreateImageController(projectname, bucketname string) ImageController {
+func CreateImageController(projectname, bucketname, dbaddress, dbport, dbname string, events EventPublisher) ImageController {
 	log.Debugf("CreateImageController started")
 	storage := repos.CreateNewStorage(projectname, bucketname)
-	controller := ImageController{storage: storage}
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := ImageController{
+		storage:  storage,
+		articles: *repos.CreateArticleRepository(dbserver, dbname),
+		events:   events,
+	}
 
 	log.Debugf("CreateImageController finished")
 	return controller
@@ -32,9 +41,9 @@ func CreateImageController(projectname, bucketname string) ImageController {
 //GetImageRoutes returns a list of images routes
 func (c *ImageController) GetImageRoutes() []Route {
 	return []Route{
-		{"GET", "/api/articles/{id}/images/{filename}", false, c.GetByFilename},
-		{"POST", "/api/articles/{id}/images", true, c.Add},
-		{"DELETE", "/api/articles/{id}/images/{filename}", true, c.DeleteByFilename},
+		{"GET", "/api/articles/{id}/images/{filename}", Public, c.GetByFilename},
+		{"POST", "/api/articles/{id}/images", services.ScopeManageImages, c.Add},
+		{"DELETE", "/api/articles/{id}/images/{filename}", services.ScopeManageImages, c.DeleteByFilename},
 	}
 }
 
@@ -57,10 +66,12 @@ func (c *ImageController) Add(w http.ResponseWriter, r *http.Request) error {
 				File:      file,
 			}
 
-			if err := c.storage.AddImage(context.Background(), image.GetPath(), image.File); err != nil {
+			if err := c.storage.AddImage(r.Context(), image.GetPath(), image.File); err != nil {
 				return err
 			}
 
+			c.events.Publish(webhooks.ImageUploaded, c.imageEvent(r.Context(), articleID, image.FileName))
+
 		}
 	}
 
@@ -81,7 +92,7 @@ func (c *ImageController) GetByFilename(w http.ResponseWriter, r *http.Request)
 		File:      nil,
 	}
 
-	imagefile, err := c.storage.GetImage(context.Background(), image.GetPath())
+	imagefile, err := c.storage.GetImage(r.Context(), image.GetPath())
 	if err != nil {
 		return err
 	}
@@ -105,11 +116,24 @@ func (c *ImageController) DeleteByFilename(w http.ResponseWriter, r *http.Reques
 		File:      nil,
 	}
 
-	if err := c.storage.DeleteImage(context.Background(), image.GetPath()); err != nil {
+	if err := c.storage.DeleteImage(r.Context(), image.GetPath()); err != nil {
 		return err
 	}
 
+	c.events.Publish(webhooks.ImageDeleted, c.imageEvent(r.Context(), articleID, filename))
+
 	w.WriteHeader(http.StatusOK)
 
 	return nil
 }
+
+//imageEvent creates the event for an image of articleID, images of unpublished articles are only streamed to
+//clients that may read drafts
+func (c *ImageController) imageEvent(ctx context.Context, articleID, filename string) webhooks.ImageEvent {
+	event := webhooks.ImageEvent{ArticleID: articleID, FileName: filename}
+	if article, err := c.articles.GetArticle(ctx, articleID); err == nil {
+		event.Published = article.IsPublished()
+	}
+
+	return event
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ericaro/frontmatter"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/articles"
+)
+
+const markdownContentType = "text/markdown"
+
+//acceptsMarkdown checks if the Accept header prefers front matter markdown over json
+func acceptsMarkdown(r *http.Request) bool {
+	markdownQuality, jsonQuality := 0.0, 0.0
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+
+		switch mediaType {
+		case markdownContentType:
+			markdownQuality = quality
+		case "application/json":
+			jsonQuality = quality
+		}
+	}
+
+	return markdownQuality > 0 && markdownQuality >= jsonQuality
+}
+
+//readArticle loads the article from a json or front matter markdown request body
+func readArticle(r *http.Request) (*articles.Article, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+	if err != nil {
+		return nil, services.NewError(err, "body is invalid", "FormatError", false)
+	}
+
+	defer r.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != markdownContentType {
+		article := &articles.Article{}
+		if err := services.NewError(
+			json.Unmarshal(body, article),
+			"error loading data while updating article",
+			"FormatError",
+			false); err != nil {
+			return nil, err
+		}
+
+		return article, nil
+	}
+
+	importArticle := &articles.ImportArticle{}
+	if err := services.NewError(
+		frontmatter.Unmarshal(body, importArticle),
+		"error loading front matter while updating article",
+		"FormatError",
+		false); err != nil {
+		return nil, err
+	}
+
+	article, err := importArticle.ToArticle()
+	if err != nil {
+		return nil, services.NewError(err, "front matter is invalid", "ValidationError", false)
+	}
+
+	return article, nil
+}
+
+//writeSavedArticle writes a created or updated article in the format requested by the Accept header
+func writeSavedArticle(w http.ResponseWriter, r *http.Request, status int, article *articles.Article) error {
+	w.Header().Add("Vary", "Accept")
+	if acceptsMarkdown(r) {
+		return writeMarkdown(w, status, article)
+	}
+
+	data, _ := json.Marshal(article)
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write(data)
+
+	return nil
+}
+
+//writeMarkdown writes the article in the front matter format used by the article importer
+func writeMarkdown(w http.ResponseWriter, status int, article *articles.Article) error {
+	data, err := frontmatter.Marshal(articles.NewImportArticle(article))
+	if err != nil {
+		return services.NewError(err, "unable to write article as markdown", "InternalError", true)
+	}
+
+	if article.Language != "" {
+		w.Header().Set("Content-Language", article.Language)
+	}
+
+	w.Header().Set("Content-Type", markdownContentType+"; charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write(data)
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/evcraddock/goarticles/internal/metrics"
+)
+
+//GetMetricsRoutes returns list of metrics routes
+func GetMetricsRoutes() []Route {
+	return []Route{
+		{"GET", "/metrics", Public, Metrics},
+	}
+}
+
+//Metrics returns request, database, storage, authentication and runtime metrics in the prometheus format
+func Metrics(w http.ResponseWriter, r *http.Request) error {
+	metrics.Handler().ServeHTTP(w, r)
+	return nil
+}
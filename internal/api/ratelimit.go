@@ -0,0 +1,225 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+	"github.com/evcraddock/goarticles/internal/services"
+)
+
+//Route groups that share a rate limit
+const (
+	PublicRateLimit        = "public"
+	ImagesRateLimit        = "images"
+	AuthRateLimit          = "auth"
+	AuthenticatedRateLimit = "authenticated"
+	PreAuthRateLimit       = "preauth"
+)
+
+const bucketSweepInterval = time.Minute
+
+//defaultRateLimits limits used for groups that are not configured
+var defaultRateLimits = map[string]string{
+	PublicRateLimit:        "300/1m",
+	ImagesRateLimit:        "600/1m",
+	AuthRateLimit:          "10/1m",
+	AuthenticatedRateLimit: "120/1m",
+	PreAuthRateLimit:       "600/1m",
+}
+
+//RateLimit number of requests allowed in each period
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+//RateLimiter limits the requests of each client with a token bucket per route group
+type RateLimiter struct {
+	limits  map[string]RateLimit
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+}
+
+//CreateRateLimiter creates limiter with the limits of each route group
+func CreateRateLimiter(config configs.RateLimitConfiguration) (*RateLimiter, error) {
+	configured := map[string]string{
+		PublicRateLimit:        config.Public,
+		ImagesRateLimit:        config.Images,
+		AuthRateLimit:          config.Auth,
+		AuthenticatedRateLimit: config.Authenticated,
+		PreAuthRateLimit:       config.PreAuth,
+	}
+
+	limiter := &RateLimiter{
+		limits:  make(map[string]RateLimit),
+		buckets: make(map[string]*tokenBucket),
+		swept:   time.Now(),
+	}
+
+	for group, value := range configured {
+		if value == "" {
+			value = defaultRateLimits[group]
+		}
+
+		if strings.EqualFold(value, "off") {
+			continue
+		}
+
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v rate limit: %v", group, err)
+		}
+
+		limiter.limits[group] = limit
+	}
+
+	return limiter, nil
+}
+
+//ParseRateLimit parses a limit written as {requests}/{period}, such as 300/1m
+func ParseRateLimit(value string) (RateLimit, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return RateLimit{}, fmt.Errorf("%v is not in the format {requests}/{period}", value)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || requests < 1 {
+		return RateLimit{}, fmt.Errorf("requests must be a positive number: %v", parts[0])
+	}
+
+	period, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || period <= 0 {
+		return RateLimit{}, fmt.Errorf("period must be a positive duration: %v", parts[1])
+	}
+
+	return RateLimit{Requests: requests, Period: period}, nil
+}
+
+//rateLimitGroup returns the group whose limit applies to route
+func rateLimitGroup(route Route) string {
+	switch {
+	case route.Path == "/oauth/token" || strings.HasPrefix(route.Path, "/api/account/"):
+		return AuthRateLimit
+	case route.Scope != Public:
+		return AuthenticatedRateLimit
+	case strings.Contains(route.Path, "/images/"):
+		return ImagesRateLimit
+	default:
+		return PublicRateLimit
+	}
+}
+
+//Limit wraps handler, rejecting requests once the client identified by key has used the group's limit
+func (l *RateLimiter) Limit(group string, key func(r *http.Request) string, handler http.Handler) http.Handler {
+	limit, ok := l.limits[group]
+	if !ok {
+		return handler
+	}
+
+	policy := fmt.Sprintf("%v;w=%v", limit.Requests, int(limit.Period.Seconds()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := key(r)
+		allowed, remaining, reset, retry := l.take(group+":"+client, limit, time.Now())
+
+		resetSeconds := int(math.Ceil(reset.Seconds()))
+		w.Header().Set("RateLimit-Policy", policy)
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+		if !allowed {
+			log.Infof("rate limit of %v requests exceeded by %v", group, client)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			AddHandler(func(w http.ResponseWriter, r *http.Request) error {
+				err := fmt.Errorf("%v exceeded the %v rate limit", client, group)
+				return services.NewError(err, "too many requests, try again later", "RateLimited", false)
+			}).ServeHTTP(w, r)
+
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+//take removes a token from the bucket of key, returning if the request is allowed, the tokens left, the
+//time until the bucket is full again and the time until the next token is added
+func (l *RateLimiter) take(key string, limit RateLimit, now time.Time) (bool, int, time.Duration, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds()
+
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+
+	reset := time.Duration((capacity - bucket.tokens) / rate * float64(time.Second))
+	retry := time.Duration(math.Max(0, 1-bucket.tokens) / rate * float64(time.Second))
+	return allowed, int(bucket.tokens), reset, retry
+}
+
+//sweep removes buckets that have not been used for longer than the longest period, they would be full again
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < bucketSweepInterval {
+		return
+	}
+
+	l.swept = now
+
+	var longest time.Duration
+	for _, limit := range l.limits {
+		if limit.Period > longest {
+			longest = limit.Period
+		}
+	}
+
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updated) > longest {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+//clientKey identifies anonymous clients by ip address
+func clientKey(r *http.Request) string {
+	return "ip:" + clientAddress(r)
+}
+
+//principalKey identifies authorized clients by token subject or api key
+func principalKey(r *http.Request) string {
+	if subject := services.TokenSubject(r); subject != "" {
+		return "sub:" + subject
+	}
+
+	return clientKey(r)
+}
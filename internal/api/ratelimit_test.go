@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected RateLimit
+		valid    bool
+	}{
+		{"300/1m", RateLimit{Requests: 300, Period: time.Minute}, true},
+		{" 10 / 30s ", RateLimit{Requests: 10, Period: 30 * time.Second}, true},
+		{"300", RateLimit{}, false},
+		{"0/1m", RateLimit{}, false},
+		{"-1/1m", RateLimit{}, false},
+		{"ten/1m", RateLimit{}, false},
+		{"10/0s", RateLimit{}, false},
+		{"10/minute", RateLimit{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			limit, err := ParseRateLimit(test.value)
+			if valid := err == nil; valid != test.valid {
+				t.Fatalf("expected valid %v, got %v", test.valid, err)
+			}
+
+			if limit != test.expected {
+				t.Fatalf("expected %+v, got %+v", test.expected, limit)
+			}
+		})
+	}
+}
+
+func TestRateLimiterTake(t *testing.T) {
+	limit := RateLimit{Requests: 2, Period: 10 * time.Second}
+	start := time.Now()
+
+	tests := []struct {
+		name      string
+		key       string
+		elapsed   time.Duration
+		allowed   bool
+		remaining int
+	}{
+		{"first request", "a", 0, true, 1},
+		{"second request", "a", 0, true, 0},
+		{"limit used", "a", time.Second, false, 0},
+		{"other client", "b", time.Second, true, 1},
+		{"token added", "a", 6 * time.Second, true, 0},
+		{"bucket refilled", "a", 30 * time.Second, true, 1},
+	}
+
+	limiter := &RateLimiter{
+		limits:  map[string]RateLimit{PublicRateLimit: limit},
+		buckets: make(map[string]*tokenBucket),
+		swept:   start,
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			allowed, remaining, _, retry := limiter.take(test.key, limit, start.Add(test.elapsed))
+			if allowed != test.allowed || remaining != test.remaining {
+				t.Fatalf("expected allowed %v with %v remaining, got %v with %v", test.allowed, test.remaining, allowed, remaining)
+			}
+
+			if remaining > 0 && retry != 0 {
+				t.Fatalf("expected no retry delay, got %v", retry)
+			}
+
+			if !allowed && retry <= 0 {
+				t.Fatal("expected a retry delay")
+			}
+		})
+	}
+}
+
+func TestRateLimiterLimit(t *testing.T) {
+	limiter, err := CreateRateLimiter(configs.RateLimitConfiguration{Public: "2/1m", Auth: "off"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := limiter.Limit(PublicRateLimit, clientKey, ok)
+
+	tests := []struct {
+		remote    string
+		status    int
+		remaining string
+	}{
+		{"203.0.113.5:1234", http.StatusOK, "1"},
+		{"203.0.113.5:1234", http.StatusOK, "0"},
+		{"203.0.113.5:1234", http.StatusTooManyRequests, "0"},
+		{"198.51.100.7:1234", http.StatusOK, "1"},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest("GET", "/api/articles", nil)
+		r.RemoteAddr = test.remote
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Fatalf("request %v: expected status %v, got %v", i, test.status, w.Code)
+		}
+
+		if actual := w.Header().Get("RateLimit-Remaining"); actual != test.remaining {
+			t.Fatalf("request %v: expected %v remaining, got %v", i, test.remaining, actual)
+		}
+
+		if actual := w.Header().Get("RateLimit-Policy"); actual != "2;w=60" {
+			t.Fatalf("request %v: expected policy 2;w=60, got %v", i, actual)
+		}
+
+		if retry := w.Header().Get("Retry-After"); (test.status == http.StatusTooManyRequests) != (retry != "") {
+			t.Fatalf("request %v: unexpected Retry-After %q", i, retry)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	limiter.Limit(AuthRateLimit, clientKey, ok).ServeHTTP(w, httptest.NewRequest("POST", "/oauth/token", nil))
+
+	if policy := w.Header().Get("RateLimit-Policy"); policy != "" {
+		t.Fatalf("expected the auth limit to be off, got policy %v", policy)
+	}
+}
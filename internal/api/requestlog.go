@@ -0,0 +1,112 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/evcraddock/goarticles/internal/metrics"
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/internal/tracing"
+)
+
+const (
+	requestIDHeader      = "X-Request-ID"
+	maximumRequestIDSize = 128
+)
+
+//LogRequests assigns each request an id, or keeps the one sent in X-Request-ID, resolves the client's address through
+//proxies, continues the trace sent in traceparent and writes one access log, span and the metrics of each request
+func LogRequests(handler http.Handler, proxies TrustedProxies) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		info := &services.RequestInfo{ID: requestID(r), IP: proxies.ClientIP(r)}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		w.Header().Set(requestIDHeader, info.ID)
+		rec := newResponseRecorder(w)
+		handler.ServeHTTP(rec, services.WithRequestInfo(r.WithContext(ctx), info))
+
+		duration := time.Since(start)
+		route := info.Route
+		if route == "" {
+			route = "unmatched"
+		}
+
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(
+			attribute.String("http.method", r.Method),
+			attribute.String("http.route", route),
+			attribute.String("http.target", r.URL.Path),
+			attribute.Int("http.status_code", rec.status),
+			attribute.String("request.id", info.ID),
+		)
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+
+		metrics.ObserveRequest(r.Method, route, rec.status, duration)
+
+		log.WithFields(log.Fields{
+			"requestId": info.ID,
+			"method":    r.Method,
+			"route":     route,
+			"path":      r.URL.Path,
+			"status":    rec.status,
+			"bytes":     rec.bytes,
+			"duration":  float64(duration.Microseconds()) / 1000,
+			"user":      info.User,
+			"ip":        info.IP,
+		}).Info("request completed")
+	})
+}
+
+//withRoute records the route template of the request for its access log
+func withRoute(route Route, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if info := services.GetRequestInfo(r); info != nil {
+			info.Route = route.Path
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+//requestID returns the id sent by the client when it is safe to log, otherwise a new id
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(requestIDHeader); isValidRequestID(id) {
+		return id
+	}
+
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(data)
+}
+
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maximumRequestIDSize {
+		return false
+	}
+
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/repos"
+)
+
+//OEmbed oEmbed provider response
+type OEmbed struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name,omitempty"`
+	ProviderName string `json:"provider_name,omitempty"`
+	ProviderURL  string `json:"provider_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	CacheAge     int    `json:"cache_age,omitempty"`
+}
+
+//SeoController model
+type SeoController struct {
+	repository repos.ArticleRepository
+	site       configs.SiteConfiguration
+}
+
+//CreateSeoController creates controller and sets routes
+func CreateSeoController(dbaddress, dbport, dbname string, site configs.SiteConfiguration) SeoController {
+	log.Debugf("CreateSeoController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := SeoController{
+		repository: *repos.CreateArticleRepository(dbserver, dbname),
+		site:       site,
+	}
+
+	log.Debugf("CreateSeoController finished")
+	return controller
+}
+
+//GetSeoRoutes return list of routes for seo metadata
+func (c *SeoController) GetSeoRoutes() []Route {
+	return []Route{
+		{"GET", "/api/articles/{id}/meta", Public, c.GetPageMetadata},
+		{"GET", "/oembed", Public, c.GetOEmbed},
+	}
+}
+
+//GetPageMetadata returns Open Graph and Twitter Card tags for an article
+func (c *SeoController) GetPageMetadata(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	article, err := c.repository.GetArticle(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(article.GetPageMetadata(c.site.Title, c.site.URL, c.site.APIURL))
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get article page metadata")
+	return nil
+}
+
+//GetOEmbed returns the oEmbed representation of the article at the requested url
+func (c *SeoController) GetOEmbed(w http.ResponseWriter, r *http.Request) error {
+	vars := r.URL.Query()
+
+	if format := vars.Get("format"); format != "" && format != "json" {
+		err := fmt.Errorf("unsupported format: %v", format)
+		return services.NewError(err, "only the json format is supported", "NotImplemented", false)
+	}
+
+	articleURL, err := url.Parse(vars.Get("url"))
+	if err != nil || articleURL.Path == "" {
+		err := fmt.Errorf("invalid url: %v", vars.Get("url"))
+		return services.NewError(err, "url parameter is required", "ValidationError", false)
+	}
+
+	siteURL, _ := url.Parse(c.site.URL)
+	path := strings.Trim(strings.TrimPrefix(articleURL.Path, strings.TrimRight(siteURL.Path, "/")), "/")
+
+	query := bson.M{"$or": []bson.M{
+		{"url": path},
+		{"url": "/" + path},
+		{"url": articleURL.String()},
+		{"canonicalurl": articleURL.String()},
+	}}
+
+	for k, v := range publishedQuery() {
+		query[k] = v
+	}
+
+	article, err := c.repository.FindArticle(r.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(OEmbed{
+		Version:      "1.0",
+		Type:         "link",
+		Title:        article.Title,
+		AuthorName:   article.Author,
+		ProviderName: c.site.Title,
+		ProviderURL:  c.site.URL,
+		ThumbnailURL: article.SocialImageURL(c.site.APIURL),
+		CacheAge:     int(feedMaxAge.Seconds()),
+	})
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get oembed")
+	return nil
+}
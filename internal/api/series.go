@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/articles"
+	"github.com/evcraddock/goarticles/pkg/repos"
+)
+
+//SeriesController model
+type SeriesController struct {
+	repository repos.SeriesRepository
+}
+
+//CreateSeriesController creates controller and sets routes
+func CreateSeriesController(dbaddress, dbport, dbname string) SeriesController {
+	log.Debugf("CreateSeriesController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	repository := repos.CreateSeriesRepository(dbserver, dbname)
+	controller := SeriesController{repository: *repository}
+
+	log.Debugf("CreateSeriesController finished")
+	return controller
+}
+
+//GetSeriesRoutes return list of routes for series
+func (c *SeriesController) GetSeriesRoutes() []Route {
+	return []Route{
+		{"GET", "/api/series", Public, c.GetAll},
+		{"GET", "/api/series/{slug}", Public, c.GetBySlug},
+		{"POST", "/api/series", services.ScopeWriteArticles, c.Add},
+		{"PUT", "/api/series/{slug}", services.ScopeWriteArticles, c.Update},
+		{"DELETE", "/api/series/{slug}", services.ScopeDeleteArticles, c.Delete},
+	}
+}
+
+//GetAll returns all series
+func (c *SeriesController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	series, err := c.repository.GetAllSeries()
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(series)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("GetAll series")
+	return nil
+}
+
+//GetBySlug returns series by slug
+func (c *SeriesController) GetBySlug(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	series, err := c.repository.GetSeries(slug)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(series)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get series by slug")
+	return nil
+}
+
+//Add adds new series
+func (c *SeriesController) Add(w http.ResponseWriter, r *http.Request) error {
+	series, err := c.readSeries(r)
+	if err != nil {
+		return err
+	}
+
+	if series.Slug == "" {
+		return services.NewError(fmt.Errorf("slug is required"), "series slug is required", "ValidationError", false)
+	}
+
+	newSeries, err := c.repository.AddSeries(*series)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(newSeries)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+
+	return nil
+}
+
+//Update updates existing series
+func (c *SeriesController) Update(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	series, err := c.readSeries(r)
+	if err != nil {
+		return err
+	}
+
+	if series.Slug != slug {
+		err := fmt.Errorf("invalid slug: %v", slug)
+		return services.NewError(err, "series slug does not match url parameter", "ValidationError", false)
+	}
+
+	updatedSeries, err := c.repository.UpdateSeries(slug, *series)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(updatedSeries)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	return nil
+}
+
+//Delete deletes requested series
+func (c *SeriesController) Delete(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	if err := c.repository.DeleteSeries(slug); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+func (c *SeriesController) readSeries(r *http.Request) (*articles.Series, error) {
+	var series articles.Series
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+	if err != nil {
+		return nil, services.NewError(err, "body is invalid", "FormatError", false)
+	}
+
+	defer r.Body.Close()
+	if err := services.NewError(
+		json.Unmarshal(body, &series),
+		"error loading data while saving series",
+		"FormatError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
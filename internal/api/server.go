@@ -12,16 +12,21 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/codes"
 
 	"github.com/evcraddock/goarticles/internal/configs"
 	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/internal/tracing"
 )
 
-//Route stores route data
+//Public scope of routes that do not require a token
+const Public = ""
+
+//Route stores route data, Scope is the permission a token needs to call the route
 type Route struct {
 	Method      string
 	Path        string
-	RequireAuth bool
+	Scope       string
 	HandlerFunc RouteHandlerFunc
 }
 
@@ -42,14 +47,31 @@ func AddHandler(handlerFunc RouteHandlerFunc) RouteHandler {
 
 //ServeHTTP required for Handler interface
 func (h RouteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	err := h.HandlerFunc(w, r)
+	name := "RouteHandler"
+	if info := services.GetRequestInfo(r); info != nil && info.Route != "" {
+		name = "RouteHandler " + info.Route
+	}
+
+	ctx, span := tracing.Start(r.Context(), name)
+	defer span.End()
+
+	err := h.HandlerFunc(w, r.WithContext(ctx))
 
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
 		switch e := err.(type) {
 		case services.Error:
-			log.Error(e.ErrorDetails())
+			services.RequestLog(r).Error(e.ErrorDetails())
+			errorType := "Error"
+			if apiError, ok := e.(*services.APIError); ok {
+				apiError.RequestID = services.RequestID(r)
+				errorType = apiError.Type
+			}
+
 			if e.ShouldDisplay() {
 				errorData, _ := json.Marshal(e)
 				w.WriteHeader(e.Status())
@@ -57,18 +79,36 @@ func (h RouteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			w.WriteHeader(e.Status())
+			//the details of hidden errors are left out but the request id is still returned
+			writeError(w, r, e.Status(), errorType)
 		default:
-			//TODO: return better error
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			services.RequestLog(r).Error(err.Error())
+			writeError(w, r, http.StatusInternalServerError, "InternalError")
 		}
 
 		return
 	}
 }
 
+//writeError writes an error with only the status text and request id
+func writeError(w http.ResponseWriter, r *http.Request, status int, errorType string) {
+	errorData, _ := json.Marshal(services.APIError{
+		Message:   http.StatusText(status),
+		Type:      errorType,
+		RequestID: services.RequestID(r),
+	})
+
+	w.WriteHeader(status)
+	w.Write(errorData)
+}
+
 //NewServer create a new http server
 func NewServer(config *configs.Configuration) {
+	shutdownTracing, err := tracing.Setup(config.Tracing)
+	if err != nil {
+		log.Fatalf("unable to configure tracing: %v", err)
+	}
+
 	router := NewRouter(config)
 
 	srv := &http.Server{
@@ -95,6 +135,10 @@ func NewServer(config *configs.Configuration) {
 	defer cancel()
 
 	srv.Shutdown(ctx)
+	if err := shutdownTracing(ctx); err != nil {
+		log.Errorf("unable to flush traces: %v", err)
+	}
+
 	log.Info("Service shutting down")
 	os.Exit(0)
 }
@@ -104,38 +148,100 @@ func NewRouter(config *configs.Configuration) http.Handler {
 	log.Debug("NewRouter started")
 	r := mux.NewRouter()
 	r.StrictSlash(true)
-	auth := services.NewAuthorization(config.Authentication.Domain, config.Authentication.Audience)
+	provider, err := services.NewAuthProvider(config.Authentication)
+	if err != nil {
+		log.Fatalf("unable to configure authentication: %v", err)
+	}
+
+	auth := services.NewAuthorization(provider, config.Authentication.Audience, config.Authentication.RolesClaim)
+
+	dispatcher := CreateWebhookDispatcher(config.Database.Address, config.Database.Port, config.Database.DatabaseName)
+	dispatcher.Start()
+
+	stream := CreateEventStream(eventHistorySize)
+	events := EventBus{dispatcher, stream}
 
 	var routes []Route
 
-	articleCtrl := CreateArticleController(config.Database.Address, config.Database.Port, config.Database.DatabaseName)
-	imageCtrl := CreateImageController(config.Storage.Project, config.Storage.Bucket)
+	articleCtrl := CreateArticleController(config.Database.Address, config.Database.Port, config.Database.DatabaseName, events)
+	imageCtrl := CreateImageController(config.Storage.Project, config.Storage.Bucket, config.Database.Address, config.Database.Port, config.Database.DatabaseName, events)
+	seriesCtrl := CreateSeriesController(config.Database.Address, config.Database.Port, config.Database.DatabaseName)
+	commentCtrl := CreateCommentController(config.Database.Address, config.Database.Port, config.Database.DatabaseName)
+	feedCtrl := CreateFeedController(config.Database.Address, config.Database.Port, config.Database.DatabaseName, config.Site)
+	sitemapCtrl := CreateSitemapController(config.Database.Address, config.Database.Port, config.Database.DatabaseName, config.Site)
+	seoCtrl := CreateSeoController(config.Database.Address, config.Database.Port, config.Database.DatabaseName, config.Site)
+	webhookCtrl := CreateWebhookController(config.Database.Address, config.Database.Port, config.Database.DatabaseName)
+	auditCtrl := CreateAuditController(config.Database.Address, config.Database.Port, config.Database.DatabaseName)
+	apiKeyCtrl := CreateAPIKeyController(config.Database.Address, config.Database.Port, config.Database.DatabaseName)
+	auth.UseAPIKeys(&apiKeyCtrl)
 
 	routes = append(routes, articleCtrl.GetArticleRoutes()...)
 	routes = append(routes, imageCtrl.GetImageRoutes()...)
+	routes = append(routes, seriesCtrl.GetSeriesRoutes()...)
+	routes = append(routes, commentCtrl.GetCommentRoutes()...)
+	routes = append(routes, feedCtrl.GetFeedRoutes()...)
+	routes = append(routes, sitemapCtrl.GetSitemapRoutes()...)
+	routes = append(routes, seoCtrl.GetSeoRoutes()...)
+	routes = append(routes, webhookCtrl.GetWebhookRoutes()...)
+	routes = append(routes, stream.GetEventRoutes()...)
+	routes = append(routes, auditCtrl.GetAuditRoutes()...)
+	routes = append(routes, apiKeyCtrl.GetAPIKeyRoutes()...)
+
+	//local users can only log in when the api issues its own tokens
+	if issuer, ok := provider.(*services.LocalIssuer); ok {
+		tokenCtrl := CreateTokenController(issuer, config.Database.Address, config.Database.Port, config.Database.DatabaseName, config.Authentication.RefreshTokenTTL)
+		userCtrl := CreateUserController(config.Database.Address, config.Database.Port, config.Database.DatabaseName)
+		routes = append(routes, tokenCtrl.GetTokenRoutes()...)
+		routes = append(routes, userCtrl.GetUserRoutes()...)
+	}
+
 	routes = append(routes, GetHealthRoutes()...)
+	routes = append(routes, GetMetricsRoutes()...)
+
+	proxies, err := ParseTrustedProxies(config.Server.TrustedProxies)
+	if err != nil {
+		log.Fatalf("unable to configure trusted proxies: %v", err)
+	}
+
+	limiter, err := CreateRateLimiter(config.RateLimit)
+	if err != nil {
+		log.Fatalf("unable to configure rate limits: %v", err)
+	}
 
 	for _, route := range routes {
 		handler := AddHandler(route.HandlerFunc)
+		group := rateLimitGroup(route)
+
+		if route.Scope != Public {
+			//audit wraps the scope check so denied writes are recorded too
+			var scoped http.Handler = auth.RequireScope(route.Scope, handler)
+			if route.Method != "GET" {
+				scoped = auditCtrl.Audit(route, scoped)
+			}
 
-		if route.RequireAuth {
-			handle := auth.Authorize(handler)
-			r.Handle(route.Path, handle).Methods(route.Method)
+			//authorized clients are limited by subject so they do not share the limit of their ip address, every
+			//request is first limited by ip address with the looser preauth limit so rejected credentials cannot be
+			//guessed without limit
+			handle := limiter.Limit(PreAuthRateLimit, clientKey, auth.Authorize(limiter.Limit(group, principalKey, scoped)))
+			r.Handle(route.Path, withRoute(route, handle)).Methods(route.Method)
 
 			continue
 		}
 
-		r.Handle(route.Path, handler).Methods(route.Method)
+		//public routes are served anonymously unless valid credentials are sent, drafts are only returned to principals
+		//with the read:drafts scope
+		r.Handle(route.Path, withRoute(route, limiter.Limit(group, clientKey, auth.Identify(handler)))).Methods(route.Method)
 	}
 
 	log.Debug("NewRouter finished")
-	return handleCORS(r)
+	return LogRequests(handleCORS(r), proxies)
 }
 
 func handleCORS(router *mux.Router) http.Handler {
-	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With"})
+	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "X-Request-ID"})
 	originsOk := handlers.AllowedOrigins([]string{os.Getenv("ORIGIN_ALLOWED")})
 	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
+	exposedOk := handlers.ExposedHeaders([]string{"RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID"})
 
 	router.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -145,5 +251,5 @@ func handleCORS(router *mux.Router) http.Handler {
 		return
 	})
 
-	return handlers.CORS(headersOk, originsOk, methodsOk)(router)
+	return handlers.CORS(headersOk, originsOk, methodsOk, exposedOk)(router)
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/repos"
+	"github.com/evcraddock/goarticles/pkg/sitemaps"
+)
+
+const defaultRobots = "User-agent: *\nAllow: /\n"
+
+//SitemapController model
+type SitemapController struct {
+	repository repos.ArticleRepository
+	site       configs.SiteConfiguration
+}
+
+//CreateSitemapController creates controller and sets routes
+func CreateSitemapController(dbaddress, dbport, dbname string, site configs.SiteConfiguration) SitemapController {
+	log.Debugf("CreateSitemapController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := SitemapController{
+		repository: *repos.CreateArticleRepository(dbserver, dbname),
+		site:       site,
+	}
+
+	log.Debugf("CreateSitemapController finished")
+	return controller
+}
+
+//GetSitemapRoutes return list of routes for sitemaps and robots.txt
+func (c *SitemapController) GetSitemapRoutes() []Route {
+	return []Route{
+		{"GET", "/sitemap.xml", Public, c.GetIndex},
+		{"GET", "/sitemaps/sitemap-{page:[0-9]+}.xml", Public, c.GetSitemap},
+		{"GET", "/robots.txt", Public, c.GetRobots},
+	}
+}
+
+//GetIndex returns the sitemap index referencing each sitemap page
+func (c *SitemapController) GetIndex(w http.ResponseWriter, r *http.Request) error {
+	count, err := c.repository.CountArticles(r.Context(), indexableQuery())
+	if err != nil {
+		return err
+	}
+
+	pages := make([]sitemaps.Page, 0)
+	for page := 1; page == 1 || (page-1)*sitemaps.MaxURLs < count; page++ {
+		pages = append(pages, sitemaps.Page{
+			Location: fmt.Sprintf("%v/sitemaps/sitemap-%v.xml", strings.TrimRight(c.site.APIURL, "/"), page),
+		})
+	}
+
+	data, err := sitemaps.NewIndex(pages)
+	if err != nil {
+		return services.NewError(err, "unable to create sitemap index", "SitemapError", false)
+	}
+
+	writeCached(w, r, "application/xml; charset=UTF-8", data, time.Time{})
+	log.Info("Get sitemap index")
+
+	return nil
+}
+
+//GetSitemap returns a page of published article urls
+func (c *SitemapController) GetSitemap(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	page, err := strconv.Atoi(vars["page"])
+	if err != nil || page < 1 {
+		return services.NewError(fmt.Errorf("invalid page: %v", vars["page"]), "sitemap does not exist", "NotFound", false)
+	}
+
+	results, err := c.repository.GetArticlesPage(r.Context(), indexableQuery(), (page-1)*sitemaps.MaxURLs, sitemaps.MaxURLs)
+	if err != nil {
+		return err
+	}
+
+	if len(*results) == 0 && page > 1 {
+		return services.NewError(fmt.Errorf("invalid page: %v", page), "sitemap does not exist", "NotFound", false)
+	}
+
+	var modified time.Time
+	entries := make([]sitemaps.Entry, 0, len(*results))
+	for i := range *results {
+		article := &(*results)[i]
+		entry := sitemaps.Entry{
+			Location:     article.CanonicalLink(c.site.URL),
+			LastModified: article.LastModified(),
+		}
+
+		if article.Banner != "" {
+			entry.Images = []string{article.ImageURL(c.site.APIURL, article.Banner)}
+		}
+
+		if article.LastModified().After(modified) {
+			modified = article.LastModified()
+		}
+
+		entries = append(entries, entry)
+	}
+
+	data, err := sitemaps.NewSitemap(entries)
+	if err != nil {
+		return services.NewError(err, "unable to create sitemap", "SitemapError", false)
+	}
+
+	writeCached(w, r, "application/xml; charset=UTF-8", data, modified)
+	log.Info("Get sitemap page")
+
+	return nil
+}
+
+//GetRobots returns the configured robots.txt with a reference to the sitemap index
+func (c *SitemapController) GetRobots(w http.ResponseWriter, r *http.Request) error {
+	robots := c.site.Robots
+	if strings.TrimSpace(robots) == "" {
+		robots = defaultRobots
+	}
+
+	if !strings.Contains(strings.ToLower(robots), "sitemap:") {
+		robots = strings.TrimRight(robots, "\n") + fmt.Sprintf("\n\nSitemap: %v/sitemap.xml\n", strings.TrimRight(c.site.APIURL, "/"))
+	}
+
+	writeCached(w, r, "text/plain; charset=UTF-8", []byte(robots), time.Time{})
+
+	return nil
+}
+
+func indexableQuery() bson.M {
+	query := publishedQuery()
+	query["noindex"] = bson.M{"$ne": true}
+
+	return query
+}
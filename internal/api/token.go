@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/metrics"
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/repos"
+	"github.com/evcraddock/goarticles/pkg/users"
+)
+
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+//TokenController issues tokens from the local issuer
+type TokenController struct {
+	issuer     *services.LocalIssuer
+	users      userStore
+	refreshTTL time.Duration
+}
+
+//userStore users and refresh tokens the token endpoint logs in with
+type userStore interface {
+	GetUserByUsername(username string) (*users.User, error)
+	GetUser(id bson.ObjectId) (*users.User, error)
+	AddRefreshToken(token users.RefreshToken) error
+	TakeRefreshToken(hash string) (*users.RefreshToken, error)
+}
+
+//tokenError error response defined by RFC 6749
+type tokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+//CreateTokenController creates controller and sets routes, refresh tokens issued to users are valid for refreshTTL
+func CreateTokenController(issuer *services.LocalIssuer, dbaddress, dbport, dbname string, refreshTTL time.Duration) TokenController {
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	return TokenController{
+		issuer:     issuer,
+		users:      repos.CreateUserRepository(dbserver, dbname),
+		refreshTTL: refreshTTL,
+	}
+}
+
+//GetTokenRoutes return list of routes for issuing tokens
+func (c *TokenController) GetTokenRoutes() []Route {
+	return []Route{
+		{"POST", "/oauth/token", Public, c.Token},
+	}
+}
+
+//Token exchanges client credentials, a user's password or a refresh token sent as json or form values for an access token
+func (c *TokenController) Token(w http.ResponseWriter, r *http.Request) error {
+	request, err := readTokenRequest(r)
+	if err != nil {
+		return writeTokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+	}
+
+	switch request.GrantType {
+	case "client_credentials":
+	case "password":
+		return c.passwordToken(w, request)
+	case "refresh_token":
+		return c.refreshToken(w, request)
+	default:
+		return writeTokenError(w, http.StatusBadRequest, "unsupported_grant_type", "grant type is not supported")
+	}
+
+	token, ttl, err := c.issuer.IssueToken(request.ClientID, request.ClientSecret, request.Audience)
+	switch err {
+	case nil:
+	case services.ErrInvalidClient:
+		log.Infof("invalid credentials for client: %v", request.ClientID)
+		metrics.AuthFailure(metrics.AuthInvalidCredentials)
+		return writeTokenError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+	case services.ErrInvalidAudience:
+		return writeTokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+	default:
+		return services.NewError(err, "unable to issue token", "InternalError", true)
+	}
+
+	return writeTokenResponse(w, services.AuthResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(ttl.Seconds()),
+	})
+}
+
+//passwordToken logs a local user in, issuing a session token and a refresh token
+func (c *TokenController) passwordToken(w http.ResponseWriter, request *services.AuthRequestBody) error {
+	user, err := c.users.GetUserByUsername(request.Username)
+	if err != nil {
+		if e, ok := err.(services.Error); !ok || e.Status() != http.StatusNotFound {
+			return err
+		}
+	}
+
+	if user == nil {
+		users.CheckUnknownUser(request.Password)
+	}
+
+	if user == nil || !user.CheckPassword(request.Password) {
+		log.Infof("invalid password for user: %v", request.Username)
+		metrics.AuthFailure(metrics.AuthInvalidCredentials)
+		return writeTokenError(w, http.StatusBadRequest, "invalid_grant", "invalid username or password")
+	}
+
+	return c.userTokens(w, user)
+}
+
+//refreshToken exchanges a refresh token for a new session, the refresh token is replaced so it can only be used once
+func (c *TokenController) refreshToken(w http.ResponseWriter, request *services.AuthRequestBody) error {
+	refresh, err := c.users.TakeRefreshToken(users.HashToken(request.RefreshToken))
+	if err != nil {
+		if e, ok := err.(services.Error); !ok || e.Status() != http.StatusNotFound {
+			return err
+		}
+
+		return writeTokenError(w, http.StatusBadRequest, "invalid_grant", "refresh token is invalid")
+	}
+
+	if time.Now().After(refresh.ExpiresAt) {
+		return writeTokenError(w, http.StatusBadRequest, "invalid_grant", "refresh token has expired")
+	}
+
+	user, err := c.users.GetUser(refresh.UserID)
+	if err != nil {
+		if e, ok := err.(services.Error); !ok || e.Status() != http.StatusNotFound {
+			return err
+		}
+
+		return writeTokenError(w, http.StatusBadRequest, "invalid_grant", "refresh token is invalid")
+	}
+
+	return c.userTokens(w, user)
+}
+
+func (c *TokenController) userTokens(w http.ResponseWriter, user *users.User) error {
+	token, ttl, err := c.issuer.IssueUserToken(user.Username, user.Roles)
+	if err != nil {
+		return services.NewError(err, "unable to issue token", "InternalError", true)
+	}
+
+	refreshToken, refresh, err := users.NewRefreshToken(user.ID, c.refreshTTL)
+	if err != nil {
+		return services.NewError(err, "unable to issue refresh token", "InternalError", true)
+	}
+
+	if err := c.users.AddRefreshToken(*refresh); err != nil {
+		return err
+	}
+
+	log.Infof("issued session token for user: %v", user.Username)
+	return writeTokenResponse(w, services.AuthResponse{
+		AccessToken:  token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(ttl.Seconds()),
+		RefreshToken: refreshToken,
+	})
+}
+
+func readTokenRequest(r *http.Request) (*services.AuthRequestBody, error) {
+	request := &services.AuthRequestBody{}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+
+		request.GrantType = r.PostForm.Get("grant_type")
+		request.ClientID = r.PostForm.Get("client_id")
+		request.ClientSecret = r.PostForm.Get("client_secret")
+		request.Audience = r.PostForm.Get("audience")
+		request.Username = r.PostForm.Get("username")
+		request.Password = r.PostForm.Get("password")
+		request.RefreshToken = r.PostForm.Get("refresh_token")
+	} else {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+		if err != nil {
+			return nil, err
+		}
+
+		defer r.Body.Close()
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, request); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	//clients may send their credentials with http basic authentication instead of the body
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		request.ClientID = clientID
+		request.ClientSecret = clientSecret
+	}
+
+	return request, nil
+}
+
+func writeTokenResponse(w http.ResponseWriter, response services.AuthResponse) error {
+	data, _ := json.Marshal(response)
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	return nil
+}
+
+func writeTokenError(w http.ResponseWriter, status int, code, description string) error {
+	data, _ := json.Marshal(tokenError{Error: code, Description: description})
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write(data)
+
+	return nil
+}
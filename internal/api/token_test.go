@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/users"
+)
+
+type memoryUserStore struct {
+	users   map[bson.ObjectId]*users.User
+	refresh map[string]users.RefreshToken
+}
+
+func (s *memoryUserStore) GetUserByUsername(username string) (*users.User, error) {
+	for _, user := range s.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+
+	return nil, services.NewError(errors.New("user not found"), "user not found", "NotFound", true)
+}
+
+func (s *memoryUserStore) GetUser(id bson.ObjectId) (*users.User, error) {
+	if user, ok := s.users[id]; ok {
+		return user, nil
+	}
+
+	return nil, services.NewError(errors.New("user not found"), "user not found", "NotFound", true)
+}
+
+func (s *memoryUserStore) AddRefreshToken(token users.RefreshToken) error {
+	s.refresh[token.ID] = token
+	return nil
+}
+
+func (s *memoryUserStore) TakeRefreshToken(hash string) (*users.RefreshToken, error) {
+	token, ok := s.refresh[hash]
+	if !ok {
+		return nil, services.NewError(errors.New("refresh token not found"), "refresh token not found", "NotFound", true)
+	}
+
+	delete(s.refresh, hash)
+	return &token, nil
+}
+
+func createTestTokenController(t *testing.T) *TokenController {
+	issuer, err := services.NewLocalIssuer(configs.AuthenticationConfiguration{
+		Provider:   services.LocalProvider,
+		SigningKey: strings.Repeat("k", 32),
+		Audience:   "goarticles",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := users.NewUser("editor", "correct horse battery", []string{services.RoleEditor})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.ID = bson.NewObjectId()
+
+	return &TokenController{
+		issuer: issuer,
+		users: &memoryUserStore{
+			users:   map[bson.ObjectId]*users.User{user.ID: user},
+			refresh: make(map[string]users.RefreshToken),
+		},
+		refreshTTL: defaultRefreshTokenTTL,
+	}
+}
+
+func requestToken(c *TokenController, form url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/oauth/token", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	AddHandler(c.Token).ServeHTTP(w, r)
+
+	return w
+}
+
+func TestPasswordLoginIssuesTokens(t *testing.T) {
+	c := createTestTokenController(t)
+
+	w := requestToken(c, url.Values{
+		"grant_type": {"password"},
+		"username":   {"editor"},
+		"password":   {"correct horse battery"},
+	})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %v", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response services.AuthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.AccessToken == "" || response.RefreshToken == "" {
+		t.Fatalf("expected an access and refresh token, got %+v", response)
+	}
+
+	w = requestToken(c, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {response.RefreshToken},
+	})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected refresh status %v, got %v: %v", http.StatusOK, w.Code, w.Body.String())
+	}
+}
+
+func TestPasswordLoginRejectsInvalidPassword(t *testing.T) {
+	c := createTestTokenController(t)
+
+	w := requestToken(c, url.Values{
+		"grant_type": {"password"},
+		"username":   {"editor"},
+		"password":   {"wrong password"},
+	})
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %v, got %v", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestPasswordLoginRejectsUnknownUser(t *testing.T) {
+	c := createTestTokenController(t)
+
+	w := requestToken(c, url.Values{
+		"grant_type": {"password"},
+		"username":   {"unknown"},
+		"password":   {"correct horse battery"},
+	})
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %v, got %v", http.StatusBadRequest, w.Code)
+	}
+}
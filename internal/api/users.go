@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+	"github.com/evcraddock/goarticles/internal/metrics"
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/internal/utils"
+	"github.com/evcraddock/goarticles/pkg/repos"
+	"github.com/evcraddock/goarticles/pkg/users"
+)
+
+//UserController model
+type UserController struct {
+	repository repos.UserRepository
+}
+
+//userRequest body sent to create a user
+type userRequest struct {
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Roles    []string `json:"roles"`
+}
+
+//passwordRequest body sent to change a password
+type passwordRequest struct {
+	Username        string `json:"username"`
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+//CreateUserController creates controller and sets routes
+func CreateUserController(dbaddress, dbport, dbname string) UserController {
+	log.Debugf("CreateUserController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := UserController{repository: *repos.CreateUserRepository(dbserver, dbname)}
+
+	log.Debugf("CreateUserController finished")
+	return controller
+}
+
+//GetUserRoutes return list of routes for local users
+func (c *UserController) GetUserRoutes() []Route {
+	return []Route{
+		{"GET", "/api/users", services.ScopeAdmin, c.GetAll},
+		{"POST", "/api/users", services.ScopeAdmin, c.Add},
+		{"DELETE", "/api/users/{id}", services.ScopeAdmin, c.Delete},
+		{"PUT", "/api/account/password", Public, c.ChangePassword},
+	}
+}
+
+//GetAll returns all users
+func (c *UserController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	results, err := c.repository.GetUsers()
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(results)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("GetAll users")
+	return nil
+}
+
+//Add creates a new user
+func (c *UserController) Add(w http.ResponseWriter, r *http.Request) error {
+	request := userRequest{}
+	if err := readJSON(r, &request); err != nil {
+		return services.NewError(err, "error loading data while saving user", "FormatError", false)
+	}
+
+	user, err := NewLocalUser(request.Username, request.Password, request.Roles)
+	if err != nil {
+		return services.NewError(err, err.Error(), "ValidationError", false)
+	}
+
+	newUser, err := c.repository.AddUser(*user)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(newUser)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+
+	log.Infof("Created user %v by %v", newUser.Username, services.TokenSubject(r))
+	return nil
+}
+
+//Delete deletes requested user
+func (c *UserController) Delete(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := c.repository.DeleteUser(id); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+
+	log.Infof("Deleted user %v by %v", id, services.TokenSubject(r))
+	return nil
+}
+
+//ChangePassword replaces a user's password after checking the current one, existing refresh tokens are revoked
+func (c *UserController) ChangePassword(w http.ResponseWriter, r *http.Request) error {
+	request := passwordRequest{}
+	if err := readJSON(r, &request); err != nil {
+		return services.NewError(err, "error loading data while changing password", "FormatError", false)
+	}
+
+	user, err := c.repository.GetUserByUsername(request.Username)
+	if err != nil {
+		if e, ok := err.(services.Error); !ok || e.Status() != http.StatusNotFound {
+			return err
+		}
+	}
+
+	if user == nil {
+		users.CheckUnknownUser(request.CurrentPassword)
+	}
+
+	if user == nil || !user.CheckPassword(request.CurrentPassword) {
+		metrics.AuthFailure(metrics.AuthInvalidCredentials)
+		err := fmt.Errorf("invalid password for user: %v", request.Username)
+		return services.NewError(err, "invalid username or password", "Authorization", false)
+	}
+
+	if err := user.SetPassword(request.NewPassword); err != nil {
+		return services.NewError(err, err.Error(), "ValidationError", false)
+	}
+
+	if err := c.repository.UpdatePassword(*user); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+
+	log.Infof("Changed password of user %v", user.Username)
+	return nil
+}
+
+//NewLocalUser creates a user after checking its roles are known
+func NewLocalUser(username, password string, roles []string) (*users.User, error) {
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("at least one role is required")
+	}
+
+	for _, role := range roles {
+		if !utils.Contains(services.Roles, role) {
+			return nil, fmt.Errorf("unknown role: %v", role)
+		}
+	}
+
+	return users.NewUser(username, password, roles)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+	if err != nil {
+		return err
+	}
+
+	defer r.Body.Close()
+	return json.Unmarshal(body, v)
+}
+
+//CreateAdmin creates an admin user, used to bootstrap a new installation
+func CreateAdmin(config *configs.Configuration, username, password string) (*users.User, error) {
+	user, err := NewLocalUser(username, password, []string{services.RoleAdmin})
+	if err != nil {
+		return nil, err
+	}
+
+	dbserver := fmt.Sprintf("%v:%v", config.Database.Address, config.Database.Port)
+	return repos.CreateUserRepository(dbserver, config.Database.DatabaseName).AddUser(*user)
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/repos"
+	"github.com/evcraddock/goarticles/pkg/webhooks"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+//WebhookController model
+type WebhookController struct {
+	repository repos.WebhookRepository
+}
+
+//CreateWebhookController creates controller and sets routes
+func CreateWebhookController(dbaddress, dbport, dbname string) WebhookController {
+	log.Debugf("CreateWebhookController started")
+	dbserver := fmt.Sprintf("%v:%v", dbaddress, dbport)
+	controller := WebhookController{repository: *repos.CreateWebhookRepository(dbserver, dbname)}
+
+	log.Debugf("CreateWebhookController finished")
+	return controller
+}
+
+//GetWebhookRoutes return list of routes for webhooks
+func (c *WebhookController) GetWebhookRoutes() []Route {
+	return []Route{
+		{"GET", "/api/webhooks", services.ScopeAdmin, c.GetAll},
+		{"GET", "/api/webhooks/{id}", services.ScopeAdmin, c.GetByID},
+		{"GET", "/api/webhooks/{id}/deliveries", services.ScopeAdmin, c.GetDeliveries},
+		{"POST", "/api/webhooks", services.ScopeAdmin, c.Add},
+		{"PUT", "/api/webhooks/{id}", services.ScopeAdmin, c.Update},
+		{"DELETE", "/api/webhooks/{id}", services.ScopeAdmin, c.Delete},
+	}
+}
+
+//GetAll returns all webhooks without their secrets
+func (c *WebhookController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	results, err := c.repository.GetWebhooks(nil)
+	if err != nil {
+		return err
+	}
+
+	for i := range *results {
+		(*results)[i].Secret = ""
+	}
+
+	data, _ := json.Marshal(results)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("GetAll webhooks")
+	return nil
+}
+
+//GetByID returns webhook by id without its secret
+func (c *WebhookController) GetByID(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	webhook, err := c.repository.GetWebhook(id)
+	if err != nil {
+		return err
+	}
+
+	webhook.Secret = ""
+
+	data, _ := json.Marshal(webhook)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get webhook by id")
+	return nil
+}
+
+//GetDeliveries returns the most recent deliveries for a webhook
+func (c *WebhookController) GetDeliveries(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := c.repository.GetWebhook(id); err != nil {
+		return err
+	}
+
+	limit := defaultDeliveryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDeliveryLimit {
+			err = fmt.Errorf("invalid limit: %v", value)
+			return services.NewError(err, "limit must be a number between 1 and 500", "ValidationError", false)
+		}
+
+		limit = parsed
+	}
+
+	deliveries, err := c.repository.GetDeliveries(id, limit)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(deliveries)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	log.Info("Get webhook deliveries")
+	return nil
+}
+
+//Add adds new webhook, a secret is generated when none is sent and returned only in this response
+func (c *WebhookController) Add(w http.ResponseWriter, r *http.Request) error {
+	webhook, err := c.readWebhook(r)
+	if err != nil {
+		return err
+	}
+
+	if webhook.Secret == "" {
+		secret, err := webhooks.GenerateSecret()
+		if err != nil {
+			return services.NewError(err, "unable to generate webhook secret", "InternalError", true)
+		}
+
+		webhook.Secret = secret
+	}
+
+	newWebhook, err := c.repository.AddWebhook(*webhook)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(newWebhook)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+
+	return nil
+}
+
+//Update updates existing webhook, the secret is kept when none is sent
+func (c *WebhookController) Update(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	existing, err := c.repository.GetWebhook(id)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := c.readWebhook(r)
+	if err != nil {
+		return err
+	}
+
+	if webhook.ID != "" && webhook.ID != existing.ID {
+		err := fmt.Errorf("invalid identifier: %v", id)
+		return services.NewError(err, "webhook id does not match url parameter", "ValidationError", false)
+	}
+
+	webhook.ID = existing.ID
+	webhook.CreatedAt = existing.CreatedAt
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+
+	updatedWebhook, err := c.repository.UpdateWebhook(*webhook)
+	if err != nil {
+		return err
+	}
+
+	updatedWebhook.Secret = ""
+
+	data, _ := json.Marshal(updatedWebhook)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+
+	return nil
+}
+
+//Delete deletes requested webhook and its delivery log
+func (c *WebhookController) Delete(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := c.repository.DeleteWebhook(id); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+func (c *WebhookController) readWebhook(r *http.Request) (*webhooks.Webhook, error) {
+	webhook := webhooks.Webhook{Active: true}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
+	if err != nil {
+		return nil, services.NewError(err, "body is invalid", "FormatError", false)
+	}
+
+	defer r.Body.Close()
+	if err := services.NewError(
+		json.Unmarshal(body, &webhook),
+		"error loading data while saving webhook",
+		"FormatError",
+		false); err != nil {
+		return nil, err
+	}
+
+	if err := webhook.ValidateWebhook(); err != nil {
+		return nil, services.NewError(err, err.Error(), "ValidationError", false)
+	}
+
+	return &webhook, nil
+}
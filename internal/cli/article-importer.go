@@ -11,11 +11,9 @@ import (
 	"net/textproto"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/ericaro/frontmatter"
 	log "github.com/sirupsen/logrus"
-	"gopkg.in/mgo.v2/bson"
 
 	"github.com/evcraddock/goarticles/internal/configs"
 	"github.com/evcraddock/goarticles/internal/services"
@@ -63,7 +61,7 @@ func (s *ArticleImporter) CreateOrUpdateArticle(filename string) {
 	s.saveArticle(inputLocation)
 }
 
-func (s *ArticleImporter) loadImportArticle(filename string) (*articles.ImportArticle, error) {
+func loadImportArticle(filename string) (*articles.ImportArticle, error) {
 	importFile, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
@@ -76,7 +74,7 @@ func (s *ArticleImporter) loadImportArticle(filename string) (*articles.ImportAr
 }
 
 func (s *ArticleImporter) saveArticle(filename string) {
-	importArticle, err := s.loadImportArticle(filename)
+	importArticle, err := loadImportArticle(filename)
 	if err != nil {
 		log.Debugf("Unable to save file: %v\n", filename)
 		log.Error(err.Error())
@@ -216,13 +214,13 @@ func (s *ArticleImporter) loadArticle(id string) (*articles.ImportArticle, error
 		return nil, err
 	}
 
-	return s.copyFrom(article)
+	return articles.NewImportArticle(article), nil
 }
 
 func (s *ArticleImporter) updateArticle(importArticle articles.ImportArticle) error {
 	url := s.URL + "/api/articles/" + importArticle.ID
 
-	article, err := s.copyTo(&importArticle)
+	article, err := importArticle.ToArticle()
 	if err != nil {
 		return err
 	}
@@ -252,7 +250,7 @@ func (s *ArticleImporter) updateArticle(importArticle articles.ImportArticle) er
 func (s *ArticleImporter) createArticle(importArticle articles.ImportArticle) (*articles.Article, error) {
 	url := s.URL + "/api/articles"
 
-	article, err := s.copyTo(&importArticle)
+	article, err := importArticle.ToArticle()
 	if err != nil {
 		return nil, err
 	}
@@ -285,44 +283,3 @@ func (s *ArticleImporter) createArticle(importArticle articles.ImportArticle) (*
 
 	return nil, fmt.Errorf("Error updating article with status: %v", res.Status)
 }
-
-func (s *ArticleImporter) copyFrom(article *articles.Article) (*articles.ImportArticle, error) {
-	importArticle := &articles.ImportArticle{
-		ID:          article.ID.Hex(),
-		Title:       article.Title,
-		URL:         article.URL,
-		Author:      article.Author,
-		Banner:      article.Banner,
-		Categories:  article.Categories,
-		Content:     article.Content,
-		PublishDate: article.PublishDate.Format("2006-01-02"),
-		Tags:        article.Tags,
-	}
-
-	return importArticle, nil
-}
-
-func (s *ArticleImporter) copyTo(importArticle *articles.ImportArticle) (*articles.Article, error) {
-	article := &articles.Article{
-		Title:      importArticle.Title,
-		URL:        importArticle.URL,
-		Author:     importArticle.Author,
-		Banner:     importArticle.Banner,
-		Categories: importArticle.Categories,
-		Content:    importArticle.Content,
-		Tags:       importArticle.Tags,
-	}
-
-	if importArticle.ID != "" {
-		article.ID = bson.ObjectIdHex(importArticle.ID)
-	}
-
-	importPublishDate, err := time.Parse("01/02/2006", importArticle.PublishDate)
-	if err != nil {
-		return nil, err
-	}
-
-	article.PublishDate = importPublishDate
-
-	return article, nil
-}
@@ -0,0 +1,448 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/evcraddock/goarticles/internal/utils"
+	"github.com/evcraddock/goarticles/pkg/articles"
+	"github.com/evcraddock/goarticles/pkg/feeds"
+	"github.com/evcraddock/goarticles/pkg/markdown"
+)
+
+const defaultLayout = "article"
+
+var (
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\(([^)\s]+)`)
+	slugInvalid   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+//SiteOptions settings for building a static site
+type SiteOptions struct {
+	Title       string
+	Description string
+	URL         string
+	APIURL      string
+	Source      string
+	Templates   string
+	Output      string
+	Drafts      bool
+}
+
+//SiteData site wide values available to every template
+type SiteData struct {
+	Title       string
+	Description string
+	URL         string
+	Tags        []string
+	Categories  []string
+}
+
+//PageData values passed to a template
+type PageData struct {
+	Site     SiteData
+	Title    string
+	Article  *ArticlePage
+	Articles []*ArticlePage
+}
+
+//ArticlePage article with its rendered content and location in the site
+type ArticlePage struct {
+	articles.Article
+	Path    string
+	Content template.HTML
+
+	images    []string
+	imageFile func(filename string) (io.ReadCloser, error)
+}
+
+//SiteBuilder renders articles through html templates into a static site
+type SiteBuilder struct {
+	options   SiteOptions
+	templates *template.Template
+	pages     []*ArticlePage
+}
+
+//NewSiteBuilder create new site builder
+func NewSiteBuilder(options SiteOptions) *SiteBuilder {
+	return &SiteBuilder{options: options}
+}
+
+//Build loads articles and writes the static site to the output folder
+func (s *SiteBuilder) Build() error {
+	templates, err := template.New("site").Funcs(template.FuncMap{
+		"formatDate": func(t time.Time, layout string) string { return t.Format(layout) },
+		"slug":       slug,
+	}).ParseGlob(filepath.Join(s.options.Templates, "*.html"))
+	if err != nil {
+		return fmt.Errorf("unable to load templates: %v", err)
+	}
+
+	s.templates = templates
+
+	if s.options.Source != "" {
+		err = s.loadLocalArticles()
+	} else {
+		err = s.loadRemoteArticles()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(s.pages, func(i, j int) bool {
+		return s.pages[i].PublishDate.After(s.pages[j].PublishDate)
+	})
+
+	if err := os.MkdirAll(s.options.Output, 0755); err != nil {
+		return err
+	}
+
+	site := s.siteData()
+	for _, page := range s.pages {
+		if err := s.writeArticle(site, page); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writeList("index", "", site.Title, s.pages, site); err != nil {
+		return err
+	}
+
+	for _, tag := range site.Tags {
+		pages := s.filter(func(page *ArticlePage) bool { return utils.Contains(page.Tags, tag) })
+		if err := s.writeList("tag", filepath.Join("tags", slug(tag)), tag, pages, site); err != nil {
+			return err
+		}
+	}
+
+	for _, category := range site.Categories {
+		pages := s.filter(func(page *ArticlePage) bool { return utils.Contains(page.Categories, category) })
+		if err := s.writeList("category", filepath.Join("categories", slug(category)), category, pages, site); err != nil {
+			return err
+		}
+	}
+
+	return s.writeFeeds()
+}
+
+func (s *SiteBuilder) loadLocalArticles() error {
+	subDirToSkip := []string{".git", ".DS_Store"}
+	return utils.IterateFolder(s.options.Source, "md", subDirToSkip, func(filename string) {
+		importArticle, err := loadImportArticle(filename)
+		if err != nil {
+			log.Errorf("unable to load %v: %v", filename, err)
+			return
+		}
+
+		article, err := importArticle.ToArticle()
+		if err != nil {
+			log.Errorf("unable to load %v: %v", filename, err)
+			return
+		}
+
+		directory := filepath.Dir(filename)
+		s.addPage(article, importArticle.Images, func(image string) (io.ReadCloser, error) {
+			return os.Open(filepath.Join(directory, image))
+		})
+	})
+}
+
+func (s *SiteBuilder) loadRemoteArticles() error {
+	res, err := http.Get(s.options.APIURL + "/api/articles")
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return fmt.Errorf("error loading articles: %v", res.Status)
+	}
+
+	results := articles.Articles{}
+	if err := json.NewDecoder(res.Body).Decode(&results); err != nil {
+		return err
+	}
+
+	for i := range results {
+		article := results[i]
+		images := referencedImages(&article)
+		s.addPage(&article, images, func(image string) (io.ReadCloser, error) {
+			res, err := http.Get(article.ImageURL(s.options.APIURL, image))
+			if err != nil {
+				return nil, err
+			}
+
+			if res.StatusCode != 200 {
+				res.Body.Close()
+				return nil, fmt.Errorf("error loading image %v: %v", image, res.Status)
+			}
+
+			return res.Body, nil
+		})
+	}
+
+	return nil
+}
+
+func (s *SiteBuilder) addPage(article *articles.Article, images []string, imageFile func(string) (io.ReadCloser, error)) {
+	if !s.options.Drafts && !article.IsPublished() {
+		log.Debugf("skipping unpublished article: %v", article.Title)
+		return
+	}
+
+	path := strings.Trim(article.URL, "/")
+	if path == "" {
+		path = slug(article.Title)
+	}
+
+	path = filepath.ToSlash(filepath.Clean(filepath.FromSlash(path)))
+	if path == "." {
+		path = slug(article.Title)
+	}
+
+	if path == ".." || strings.HasPrefix(path, "../") {
+		log.Errorf("skipping article %v: url %v is outside the site", article.Title, article.URL)
+		return
+	}
+
+	article.SetReadingMetadata()
+	s.pages = append(s.pages, &ArticlePage{
+		Article:   *article,
+		Path:      "/" + path + "/",
+		images:    images,
+		imageFile: imageFile,
+	})
+}
+
+func (s *SiteBuilder) writeArticle(site SiteData, page *ArticlePage) error {
+	html, err := markdown.Render(page.Article.Content)
+	if err != nil {
+		return fmt.Errorf("unable to render %v: %v", page.Title, err)
+	}
+
+	directory := filepath.Join(s.options.Output, filepath.FromSlash(page.Path))
+	if relative, err := filepath.Rel(s.options.Output, directory); err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("invalid page path: %v", page.Path)
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	for _, image := range page.images {
+		if err := copyImage(page, image, directory); err != nil {
+			log.Error(err.Error())
+		}
+	}
+
+	page.Content = template.HTML(markdown.RewriteImages(html, func(src string) string {
+		if strings.Contains(src, "://") || strings.HasPrefix(src, "/") {
+			return src
+		}
+
+		return page.Path + strings.TrimPrefix(src, "./")
+	}))
+
+	layout := page.Layout
+	if layout == "" {
+		layout = defaultLayout
+	}
+
+	data := PageData{Site: site, Title: page.Title, Article: page}
+	return s.execute(layout+".html", filepath.Join(directory, "index.html"), data)
+}
+
+func (s *SiteBuilder) writeList(layout, path, title string, pages []*ArticlePage, site SiteData) error {
+	if s.templates.Lookup(layout+".html") == nil {
+		log.Debugf("no %v.html template, skipping %v", layout, title)
+		return nil
+	}
+
+	directory := filepath.Join(s.options.Output, path)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	data := PageData{Site: site, Title: title, Articles: pages}
+	return s.execute(layout+".html", filepath.Join(directory, "index.html"), data)
+}
+
+func (s *SiteBuilder) writeFeeds() error {
+	siteURL := strings.TrimRight(s.options.URL, "/")
+	feed := &feeds.Feed{
+		Title:       s.options.Title,
+		Description: s.options.Description,
+		Link:        siteURL + "/",
+		FeedURL:     siteURL + "/feed.xml",
+	}
+
+	for _, page := range s.pages {
+		item := feeds.Item{
+			ID:         siteURL + page.Path,
+			Title:      page.Title,
+			Link:       siteURL + page.Path,
+			Author:     page.Author,
+			Summary:    page.Excerpt,
+			Content:    strings.Replace(string(page.Content), `src="/`, `src="`+siteURL+"/", -1),
+			Published:  page.PublishDate,
+			Categories: page.Categories,
+			Tags:       page.Tags,
+		}
+
+		if page.Banner != "" {
+			item.Image = siteURL + page.Path + page.Banner
+		}
+
+		if page.PublishDate.After(feed.Updated) {
+			feed.Updated = page.PublishDate
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	rss, err := feed.RSS()
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(s.options.Output, "feed.xml"), rss, 0644); err != nil {
+		return err
+	}
+
+	feed.FeedURL = siteURL + "/atom.xml"
+	atom, err := feed.Atom()
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(s.options.Output, "atom.xml"), atom, 0644); err != nil {
+		return err
+	}
+
+	feed.FeedURL = siteURL + "/feed.json"
+	jsonFeed, err := feed.JSON()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(s.options.Output, "feed.json"), jsonFeed, 0644)
+}
+
+func (s *SiteBuilder) execute(name, filename string, data PageData) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if err := s.templates.ExecuteTemplate(file, name, data); err != nil {
+		return fmt.Errorf("unable to render %v with %v: %v", filename, name, err)
+	}
+
+	fmt.Printf("successfully wrote page: %v \n", filename)
+	return nil
+}
+
+func (s *SiteBuilder) siteData() SiteData {
+	site := SiteData{
+		Title:       s.options.Title,
+		Description: s.options.Description,
+		URL:         s.options.URL,
+	}
+
+	for _, page := range s.pages {
+		for _, tag := range page.Tags {
+			if !utils.Contains(site.Tags, tag) {
+				site.Tags = append(site.Tags, tag)
+			}
+		}
+
+		for _, category := range page.Categories {
+			if !utils.Contains(site.Categories, category) {
+				site.Categories = append(site.Categories, category)
+			}
+		}
+	}
+
+	sort.Strings(site.Tags)
+	sort.Strings(site.Categories)
+
+	return site
+}
+
+func (s *SiteBuilder) filter(include func(page *ArticlePage) bool) []*ArticlePage {
+	pages := make([]*ArticlePage, 0)
+	for _, page := range s.pages {
+		if include(page) {
+			pages = append(pages, page)
+		}
+	}
+
+	return pages
+}
+
+func copyImage(page *ArticlePage, image, directory string) error {
+	if strings.Contains(image, "..") {
+		return fmt.Errorf("invalid image path: %v", image)
+	}
+
+	source, err := page.imageFile(image)
+	if err != nil {
+		return err
+	}
+
+	defer source.Close()
+
+	filename := filepath.Join(directory, filepath.FromSlash(strings.TrimPrefix(image, "./")))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	destination, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	defer destination.Close()
+
+	_, err = io.Copy(destination, source)
+	return err
+}
+
+func referencedImages(article *articles.Article) []string {
+	images := make([]string, 0)
+	for _, image := range []string{article.Banner, article.SocialImage} {
+		if image != "" && !utils.Contains(images, image) {
+			images = append(images, image)
+		}
+	}
+
+	for _, match := range markdownImage.FindAllStringSubmatch(article.Content, -1) {
+		image := match[1]
+		if strings.Contains(image, "://") || strings.HasPrefix(image, "/") || utils.Contains(images, image) {
+			continue
+		}
+
+		images = append(images, image)
+	}
+
+	return images
+}
+
+func slug(value string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
@@ -2,6 +2,7 @@ package configs
 
 import (
 	"os"
+	"strings"
 	"time"
 
 	"io/ioutil"
@@ -16,13 +17,19 @@ type Configuration struct {
 	Database       DatabaseConfiguration       `yaml:"database"`
 	Authentication AuthenticationConfiguration `yaml:"authentication"`
 	Storage        StorageConfiguration        `yaml:"storage"`
+	Site           SiteConfiguration           `yaml:"site"`
+	RateLimit      RateLimitConfiguration      `yaml:"ratelimit"`
+	Tracing        TracingConfiguration        `yaml:"tracing"`
 }
 
 //ServerConfiguration server config data
 type ServerConfiguration struct {
-	Port     string        `yaml:"port"`
-	LogLevel string        `yaml:"loglevel"`
-	Timeout  time.Duration `yaml:"timeout"`
+	Port      string        `yaml:"port"`
+	LogLevel  string        `yaml:"loglevel"`
+	LogFormat string        `yaml:"logformat"`
+	Timeout   time.Duration `yaml:"timeout"`
+	//TrustedProxies addresses or cidr ranges of proxies whose X-Forwarded-For header is trusted
+	TrustedProxies []string `yaml:"trustedproxies"`
 }
 
 //DatabaseConfiguration database config data
@@ -35,8 +42,27 @@ type DatabaseConfiguration struct {
 
 //AuthenticationConfiguration authentication config data
 type AuthenticationConfiguration struct {
-	Domain   string `yaml:"domain"`
-	Audience string `yaml:"audience"`
+	Provider        string              `yaml:"provider"`
+	Domain          string              `yaml:"domain"`
+	Audience        string              `yaml:"audience"`
+	Issuer          string              `yaml:"issuer"`
+	JWKSURL         string              `yaml:"jwksurl"`
+	JWKSTTL         time.Duration       `yaml:"jwksttl"`
+	SigningMethod   string              `yaml:"signingmethod"`
+	SigningKey      string              `yaml:"signingkey"`
+	SigningKeyFile  string              `yaml:"signingkeyfile"`
+	TokenTTL        time.Duration       `yaml:"tokenttl"`
+	RefreshTokenTTL time.Duration       `yaml:"refreshtokenttl"`
+	RolesClaim      string              `yaml:"rolesclaim"`
+	Clients         []ClientCredentials `yaml:"clients"`
+}
+
+//ClientCredentials client allowed to request tokens from the local issuer
+type ClientCredentials struct {
+	ID     string   `yaml:"id"`
+	Secret string   `yaml:"secret"`
+	Scopes []string `yaml:"scopes"`
+	Roles  []string `yaml:"roles"`
 }
 
 //StorageConfiguration storage config data
@@ -45,6 +71,32 @@ type StorageConfiguration struct {
 	Bucket  string `yaml:"bucketname"`
 }
 
+//SiteConfiguration public site config data
+type SiteConfiguration struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	URL         string `yaml:"url"`
+	APIURL      string `yaml:"apiurl"`
+	Robots      string `yaml:"robots"`
+}
+
+//RateLimitConfiguration requests allowed per client for each group of routes, written as {requests}/{period}
+//such as 300/1m, or off to disable the limit
+type RateLimitConfiguration struct {
+	Public        string `yaml:"public"`
+	Images        string `yaml:"images"`
+	Auth          string `yaml:"auth"`
+	Authenticated string `yaml:"authenticated"`
+	PreAuth       string `yaml:"preauth"`
+}
+
+//TracingConfiguration where OpenTelemetry spans are exported
+type TracingConfiguration struct {
+	Exporter    string `yaml:"exporter"`
+	Endpoint    string `yaml:"endpoint"`
+	ServiceName string `yaml:"servicename"`
+}
+
 //LoadConfigFile load from file
 func LoadConfigFile(filename string) (*Configuration, error) {
 
@@ -73,11 +125,42 @@ func LoadEnvironmentVariables() (*Configuration, error) {
 		return nil, err
 	}
 
+	var tokenTTL, refreshTokenTTL, jwksTTL time.Duration
+	if value := os.Getenv("GOA_AUTH_TOKEN_TTL"); value != "" {
+		if tokenTTL, err = time.ParseDuration(value); err != nil {
+			return nil, err
+		}
+	}
+
+	if value := os.Getenv("GOA_AUTH_REFRESH_TOKEN_TTL"); value != "" {
+		if refreshTokenTTL, err = time.ParseDuration(value); err != nil {
+			return nil, err
+		}
+	}
+
+	if value := os.Getenv("GOA_AUTH_JWKS_TTL"); value != "" {
+		if jwksTTL, err = time.ParseDuration(value); err != nil {
+			return nil, err
+		}
+	}
+
+	var clients []ClientCredentials
+	if clientID := os.Getenv("GOA_AUTH_CLIENT_ID"); clientID != "" {
+		clients = append(clients, ClientCredentials{
+			ID:     clientID,
+			Secret: os.Getenv("GOA_AUTH_CLIENT_SECRET"),
+			Scopes: strings.Fields(os.Getenv("GOA_AUTH_CLIENT_SCOPES")),
+			Roles:  strings.Fields(os.Getenv("GOA_AUTH_CLIENT_ROLES")),
+		})
+	}
+
 	return &Configuration{
 		ServerConfiguration{
-			Port:     os.Getenv("GOA_SERVER_PORT"),
-			LogLevel: os.Getenv("GOA_LOG_LEVEL"),
-			Timeout:  timeout,
+			Port:           os.Getenv("GOA_SERVER_PORT"),
+			LogLevel:       os.Getenv("GOA_LOG_LEVEL"),
+			LogFormat:      os.Getenv("GOA_LOG_FORMAT"),
+			Timeout:        timeout,
+			TrustedProxies: strings.Fields(os.Getenv("GOA_TRUSTED_PROXIES")),
 		},
 		DatabaseConfiguration{
 			Address:      os.Getenv("GOA_DB_ADDRESS"),
@@ -86,12 +169,42 @@ func LoadEnvironmentVariables() (*Configuration, error) {
 			Timeout:      timeout,
 		},
 		AuthenticationConfiguration{
-			Domain:   os.Getenv("GOA_AUTH_DOMAIN"),
-			Audience: os.Getenv("GOA_AUTH_AUDIENCE"),
+			Provider:        os.Getenv("GOA_AUTH_PROVIDER"),
+			Domain:          os.Getenv("GOA_AUTH_DOMAIN"),
+			Audience:        os.Getenv("GOA_AUTH_AUDIENCE"),
+			Issuer:          os.Getenv("GOA_AUTH_ISSUER"),
+			JWKSURL:         os.Getenv("GOA_AUTH_JWKS_URL"),
+			JWKSTTL:         jwksTTL,
+			SigningMethod:   os.Getenv("GOA_AUTH_SIGNING_METHOD"),
+			SigningKey:      os.Getenv("GOA_AUTH_SIGNING_KEY"),
+			SigningKeyFile:  os.Getenv("GOA_AUTH_SIGNING_KEY_FILE"),
+			TokenTTL:        tokenTTL,
+			RefreshTokenTTL: refreshTokenTTL,
+			RolesClaim:      os.Getenv("GOA_AUTH_ROLES_CLAIM"),
+			Clients:         clients,
 		},
 		StorageConfiguration{
 			Project: os.Getenv("GOA_GCP_PROJECTID"),
 			Bucket:  os.Getenv("GOA_GCP_BUCKETNAME"),
 		},
+		SiteConfiguration{
+			Title:       os.Getenv("GOA_SITE_TITLE"),
+			Description: os.Getenv("GOA_SITE_DESCRIPTION"),
+			URL:         os.Getenv("GOA_SITE_URL"),
+			APIURL:      os.Getenv("GOA_SITE_API_URL"),
+			Robots:      os.Getenv("GOA_SITE_ROBOTS"),
+		},
+		RateLimitConfiguration{
+			Public:        os.Getenv("GOA_RATELIMIT_PUBLIC"),
+			Images:        os.Getenv("GOA_RATELIMIT_IMAGES"),
+			Auth:          os.Getenv("GOA_RATELIMIT_AUTH"),
+			Authenticated: os.Getenv("GOA_RATELIMIT_AUTHENTICATED"),
+			PreAuth:       os.Getenv("GOA_RATELIMIT_PREAUTH"),
+		},
+		TracingConfiguration{
+			Exporter:    os.Getenv("GOA_TRACING_EXPORTER"),
+			Endpoint:    os.Getenv("GOA_TRACING_ENDPOINT"),
+			ServiceName: os.Getenv("GOA_TRACING_SERVICE_NAME"),
+		},
 	}, nil
 }
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "goarticles"
+
+//Reasons requests fail authentication or authorization
+const (
+	AuthUnauthorized       = "unauthorized"
+	AuthForbidden          = "forbidden"
+	AuthInvalidCredentials = "invalid_credentials"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of http requests by route template and status.",
+	}, []string{"method", "route", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to respond to http requests by route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	databaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "Time taken by article repository operations, including connecting to MongoDB.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Time taken by image storage operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	storageBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_bytes_total",
+		Help:      "Bytes written to and read from image storage.",
+	}, []string{"operation"})
+
+	authFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_failures_total",
+		Help:      "Number of requests rejected by authentication or authorization.",
+	}, []string{"reason"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests,
+		requestDuration,
+		databaseDuration,
+		storageDuration,
+		storageBytes,
+		authFailures,
+	)
+}
+
+//Handler serves the metrics in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+//ObserveRequest records a completed http request
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	requests.WithLabelValues(method, route, code).Inc()
+	requestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+//ObserveDatabase records the time taken by a database operation that started at start
+func ObserveDatabase(operation string, start time.Time) {
+	databaseDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+//ObserveStorage records the time taken by a storage operation that started at start
+func ObserveStorage(operation string, start time.Time) {
+	storageDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+//AddStorageBytes records bytes transferred by a storage operation
+func AddStorageBytes(operation string, bytes int64) {
+	storageBytes.WithLabelValues(operation).Add(float64(bytes))
+}
+
+//AuthFailure records a request rejected for reason
+func AuthFailure(reason string) {
+	authFailures.WithLabelValues(reason).Inc()
+}
@@ -18,6 +18,7 @@ type APIError struct {
 	Message       string `json:"message"`
 	MessageDetail string `json:"message-detail,omitempty"`
 	Type          string `json:"type"`
+	RequestID     string `json:"requestId,omitempty"`
 	Code          int    `json:"-"`
 	Private       bool   `json:"-"`
 	InnerMessage  string `json:"-"`
@@ -56,10 +57,16 @@ func NewError(err error, message string, errorType string, private bool) *APIErr
 		apiError.Code = 503
 	case "DATABASEERROR":
 		apiError.Code = 400
+	case "FORBIDDEN":
+		apiError.Code = 403
 	case "FORMATERROR":
 		apiError.Code = 400
 	case "NOTFOUND":
 		apiError.Code = 404
+	case "NOTIMPLEMENTED":
+		apiError.Code = 501
+	case "RATELIMITED":
+		apiError.Code = 429
 	case "VALIDATIONERROR":
 		apiError.Code = 400
 	default:
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+)
+
+//Authentication providers
+const (
+	Auth0Provider = "auth0"
+	OIDCProvider  = "oidc"
+	LocalProvider = "local"
+)
+
+//Provider identity provider that issues the tokens accepted by the api
+type Provider interface {
+	Issuer() string
+	SigningMethods() []string
+	Key(token *jwt.Token) (interface{}, error)
+}
+
+//JWKSProvider validates tokens from an issuer that publishes its signing keys as a JWKS
+type JWKSProvider struct {
+	issuer string
+	keys   *KeySet
+}
+
+//NewAuthProvider creates the provider selected by the authentication configuration, Auth0 when none is set
+func NewAuthProvider(config configs.AuthenticationConfiguration) (Provider, error) {
+	switch strings.ToLower(config.Provider) {
+	case "", Auth0Provider:
+		issuer := config.Issuer
+		if issuer == "" {
+			issuer = "https://" + config.Domain + "/"
+		}
+
+		return NewJWKSProvider(issuer, config.JWKSURL, config.JWKSTTL), nil
+	case OIDCProvider:
+		if config.Issuer == "" {
+			return nil, fmt.Errorf("issuer is required for the %v provider", OIDCProvider)
+		}
+
+		return NewJWKSProvider(config.Issuer, config.JWKSURL, config.JWKSTTL), nil
+	case LocalProvider:
+		return NewLocalIssuer(config)
+	default:
+		return nil, fmt.Errorf("unknown authentication provider: %v", config.Provider)
+	}
+}
+
+//NewJWKSProvider create provider for issuer, keys are loaded from jwksURL or {issuer}/.well-known/jwks.json
+func NewJWKSProvider(issuer, jwksURL string, ttl time.Duration) *JWKSProvider {
+	if jwksURL == "" {
+		jwksURL = strings.TrimRight(issuer, "/") + "/.well-known/jwks.json"
+	}
+
+	return &JWKSProvider{
+		issuer: issuer,
+		keys:   NewKeySet(jwksURL, ttl),
+	}
+}
+
+//Issuer returns the issuer claim of accepted tokens
+func (p *JWKSProvider) Issuer() string {
+	return p.issuer
+}
+
+//SigningMethods returns the RSA and EC algorithms accepted for tokens
+func (p *JWKSProvider) SigningMethods() []string {
+	return []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+}
+
+//Key returns the public key the token was signed with
+func (p *JWKSProvider) Key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := p.keys.Key(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	//the key type must match the algorithm so an RSA key can not be used to verify an EC signature
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); ok {
+			return key, nil
+		}
+	case *ecdsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodECDSA); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("key %v can not be used with %v", kid, token.Method.Alg())
+}
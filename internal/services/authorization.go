@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
@@ -11,41 +13,45 @@ import (
 
 	"github.com/auth0/go-jwt-middleware"
 	"github.com/dgrijalva/jwt-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/evcraddock/goarticles/internal/metrics"
+	"github.com/evcraddock/goarticles/internal/tracing"
+	"github.com/evcraddock/goarticles/internal/utils"
 )
 
+const apiKeyScheme = "apikey"
+
+//APIKeyValidator looks up the principal an api key was issued to
+type APIKeyValidator interface {
+	ValidateAPIKey(key string) (*Principal, error)
+}
+
 //Authorization values for validating token
 type Authorization struct {
-	domain     string
+	provider   Provider
 	audience   string
+	rolesClaim string
+	apiKeys    APIKeyValidator
 	middleware *jwtmiddleware.JWTMiddleware
 }
 
-//Jwks json web key collection
-type Jwks struct {
-	Keys []JSONWebKeys `json:"keys"`
-}
-
-//JSONWebKeys json web key properties
-type JSONWebKeys struct {
-	Kty string   `json:"kty"`
-	Kid string   `json:"kid"`
-	Use string   `json:"use"`
-	N   string   `json:"n"`
-	E   string   `json:"e"`
-	X5c []string `json:"x5c"`
-}
-
-//NewAuthorization create new authorization
-func NewAuthorization(domain, audience string) Authorization {
+//NewAuthorization create new authorization for tokens issued by provider, roles are read from rolesClaim
+func NewAuthorization(provider Provider, audience, rolesClaim string) Authorization {
 	log.Debug("NewAuthorization started")
+	if rolesClaim == "" {
+		rolesClaim = defaultRolesClaim
+	}
+
 	auth := Authorization{
-		domain:   domain,
-		audience: audience,
+		provider:   provider,
+		audience:   audience,
+		rolesClaim: rolesClaim,
 	}
 
 	auth.middleware = jwtmiddleware.New(jwtmiddleware.Options{
 		ValidationKeyGetter: auth.validateToken,
-		SigningMethod:       jwt.SigningMethodRS256,
 		ErrorHandler:        NotAuthorizedError,
 	})
 
@@ -53,13 +59,204 @@ func NewAuthorization(domain, audience string) Authorization {
 	return auth
 }
 
-//Authorize wraps handler with authorization middleware
+//UseAPIKeys accepts api keys checked by validator alongside bearer tokens
+func (auth *Authorization) UseAPIKeys(validator APIKeyValidator) {
+	auth.apiKeys = validator
+}
+
+//Authorize wraps handler with authorization middleware, the principal of the bearer token or api key is added to the request
 func (auth *Authorization) Authorize(handler http.Handler) http.Handler {
-	return auth.middleware.Handler(handler)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, principal, ok := auth.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		if token != nil {
+			r = r.WithContext(context.WithValue(r.Context(), "user", token))
+		}
+
+		handler.ServeHTTP(w, withPrincipal(r, principal))
+	})
+}
+
+//Identify wraps a public handler, adding the principal of a valid bearer token or api key to the request, requests
+//without credentials or with credentials that are rejected are served anonymously
+func (auth *Authorization) Identify(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		token, principal, err := auth.identify(r)
+		if err != nil || principal == nil {
+			RequestLog(r).Debugf("serving %v %v anonymously: %v", r.Method, r.URL.Path, err)
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if token != nil {
+			r = r.WithContext(context.WithValue(r.Context(), "user", token))
+		}
+
+		handler.ServeHTTP(w, withPrincipal(r, principal))
+	})
+}
+
+//identify returns the principal of the api key or bearer token of the request without writing a response
+func (auth *Authorization) identify(r *http.Request) (*jwt.Token, *Principal, error) {
+	if key, ok := apiKeyFromHeader(r); ok {
+		if auth.apiKeys == nil {
+			return nil, nil, fmt.Errorf("api keys are not enabled")
+		}
+
+		principal, err := auth.apiKeys.ValidateAPIKey(key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, principal, nil
+	}
+
+	token, err := auth.ParseToken(r)
+	if err != nil || token == nil {
+		return nil, nil, err
+	}
+
+	return token, auth.TokenPrincipal(token), nil
+}
+
+//authenticate validates the api key or bearer token of the request, writing the error response when it is rejected
+func (auth *Authorization) authenticate(w http.ResponseWriter, r *http.Request) (*jwt.Token, *Principal, bool) {
+	ctx, span := tracing.Start(r.Context(), "Authorization.Authorize")
+	defer span.End()
+
+	key, ok := apiKeyFromHeader(r)
+	if !ok {
+		span.SetAttributes(attribute.String("auth.scheme", "bearer"))
+
+		//CheckJWT replaces the request it is given with one carrying the token
+		tokenRequest := r.WithContext(ctx)
+		if err := auth.middleware.CheckJWT(w, tokenRequest); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, nil, false
+		}
+
+		token, _ := tokenRequest.Context().Value("user").(*jwt.Token)
+		return token, auth.TokenPrincipal(token), true
+	}
+
+	span.SetAttributes(attribute.String("auth.scheme", apiKeyScheme))
+	if auth.apiKeys == nil {
+		span.SetStatus(codes.Error, "api keys are not enabled")
+		NotAuthorizedError(w, r, "api keys are not enabled")
+		return nil, nil, false
+	}
+
+	principal, err := auth.apiKeys.ValidateAPIKey(key)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		RequestLog(r).Infof("api key rejected for %v %v: %v", r.Method, r.URL.Path, err)
+		NotAuthorizedError(w, r, err.Error())
+		return nil, nil, false
+	}
+
+	return nil, principal, true
+}
+
+//ParseToken validates the bearer token on the request, returning nil when no token was sent
+func (auth *Authorization) ParseToken(r *http.Request) (*jwt.Token, error) {
+	_, span := tracing.Start(r.Context(), "Authorization.ParseToken")
+	defer span.End()
+
+	tokenString, err := jwtmiddleware.FromAuthHeader(r)
+	if err != nil {
+		return nil, NewError(err, "unable to validate token", "Authorization", true)
+	}
+
+	if tokenString == "" {
+		return nil, nil
+	}
+
+	token, err := jwt.Parse(tokenString, auth.validateToken)
+	if err != nil {
+		return nil, NewError(err, "unable to validate token", "Authorization", true)
+	}
+
+	if !token.Valid {
+		return nil, NewError(fmt.Errorf("invalid token"), "unable to validate token", "Authorization", true)
+	}
+
+	return token, nil
+}
+
+//TokenSubject returns the subject of an authorized request
+func TokenSubject(r *http.Request) string {
+	if principal := RequestPrincipal(r); principal != nil {
+		return principal.Subject
+	}
+
+	token, ok := r.Context().Value("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+
+	return ClaimsSubject(token)
+}
+
+//TokenClientID returns the client the token or api key of an authorized request was issued to
+func TokenClientID(r *http.Request) string {
+	if principal := RequestPrincipal(r); principal != nil {
+		return principal.ClientID
+	}
+
+	token, ok := r.Context().Value("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+
+	if clientID, _ := claims["azp"].(string); clientID != "" {
+		return clientID
+	}
+
+	clientID, _ := claims["client_id"].(string)
+	return clientID
+}
+
+//ClaimsSubject returns the subject claim of a token
+func ClaimsSubject(token *jwt.Token) string {
+	if token == nil {
+		return ""
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+
+	subject, _ := claims["sub"].(string)
+	return subject
+}
+
+//apiKeyFromHeader returns the key sent as "Authorization: ApiKey {key}"
+func apiKeyFromHeader(r *http.Request) (string, bool) {
+	parts := strings.Fields(r.Header.Get("Authorization"))
+	if len(parts) != 2 || strings.ToLower(parts[0]) != apiKeyScheme {
+		return "", false
+	}
+
+	return parts[1], true
 }
 
 //NotAuthorizedError authorization error handler
 func NotAuthorizedError(w http.ResponseWriter, r *http.Request, err string) {
+	metrics.AuthFailure(metrics.AuthUnauthorized)
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
 	apiError := NewError(
@@ -67,6 +264,7 @@ func NotAuthorizedError(w http.ResponseWriter, r *http.Request, err string) {
 		"unable to validate token",
 		"Authorization",
 		true)
+	apiError.RequestID = RequestID(r)
 
 	errorData, _ := json.Marshal(apiError)
 	w.WriteHeader(apiError.Status())
@@ -74,6 +272,10 @@ func NotAuthorizedError(w http.ResponseWriter, r *http.Request, err string) {
 }
 
 func (auth *Authorization) validateToken(token *jwt.Token) (interface{}, error) {
+	if !utils.Contains(auth.provider.SigningMethods(), token.Method.Alg()) {
+		return token, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+	}
+
 	aud := auth.audience
 	checkAud := token.Claims.(jwt.MapClaims).VerifyAudience(aud, false)
 
@@ -81,51 +283,12 @@ func (auth *Authorization) validateToken(token *jwt.Token) (interface{}, error)
 		return token, errors.New("invalid audience")
 	}
 
-	iss := "https://" + auth.domain + "/"
+	iss := auth.provider.Issuer()
 	checkIss := token.Claims.(jwt.MapClaims).VerifyIssuer(iss, false)
 
 	if !checkIss {
 		return token, errors.New("invalid issuer")
 	}
 
-	cert, err := auth.getPemCert(token)
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-
-	result, _ := jwt.ParseRSAPublicKeyFromPEM([]byte(cert))
-	return result, nil
-}
-
-func (auth *Authorization) getPemCert(token *jwt.Token) (string, error) {
-	cert := ""
-	resp, err := http.Get("https://" + auth.domain + "/.well-known/jwks.json")
-
-	if err != nil {
-		log.Debug(err)
-		return cert, err
-	}
-
-	defer resp.Body.Close()
-
-	var jwks = Jwks{}
-	err = json.NewDecoder(resp.Body).Decode(&jwks)
-
-	if err != nil {
-		return cert, err
-	}
-
-	for k := range jwks.Keys {
-		if token.Header["kid"] == jwks.Keys[k].Kid {
-			cert = "-----BEGIN CERTIFICATE-----\n" + jwks.Keys[k].X5c[0] + "\n-----END CERTIFICATE-----"
-		}
-	}
-
-	if cert == "" {
-		err := errors.New("unable to find appropriate key")
-		return cert, err
-	}
-
-	return cert, nil
+	return auth.provider.Key(token)
 }
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultKeySetTTL      = time.Hour
+	minimumRefreshWaiting = 30 * time.Second
+	keySetTimeout         = 10 * time.Second
+)
+
+//Jwks json web key collection
+type Jwks struct {
+	Keys []JSONWebKeys `json:"keys"`
+}
+
+//JSONWebKeys json web key properties
+type JSONWebKeys struct {
+	Kty string   `json:"kty"`
+	Kid string   `json:"kid"`
+	Use string   `json:"use"`
+	Alg string   `json:"alg"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	Crv string   `json:"crv"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	X5c []string `json:"x5c"`
+}
+
+//KeySet caches the signing keys published at a JWKS url
+type KeySet struct {
+	url         string
+	ttl         time.Duration
+	client      *http.Client
+	mutex       sync.Mutex
+	keys        map[string]interface{}
+	fetched     time.Time
+	lastRefresh time.Time
+	refreshing  chan struct{}
+}
+
+//NewKeySet create key set for url, keys are fetched again after ttl
+func NewKeySet(url string, ttl time.Duration) *KeySet {
+	if ttl <= 0 {
+		ttl = defaultKeySetTTL
+	}
+
+	return &KeySet{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: keySetTimeout},
+		keys:   make(map[string]interface{}),
+	}
+}
+
+//Key returns the public key for kid, refreshing the keys when they have expired or kid is unknown
+func (k *KeySet) Key(kid string) (interface{}, error) {
+	k.mutex.Lock()
+	key, ok := k.keys[kid]
+	expired := time.Since(k.fetched) > k.ttl
+	k.mutex.Unlock()
+
+	if ok {
+		//expired keys are still used while they are refreshed in the background
+		if expired {
+			go k.refresh()
+		}
+
+		return key, nil
+	}
+
+	//keys may have been rotated, refetch them and wait for the result
+	k.refresh()
+
+	k.mutex.Lock()
+	key, ok = k.keys[kid]
+	k.mutex.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unable to find appropriate key: %v", kid)
+}
+
+//refresh replaces the cached keys, but not more often than minimumRefreshWaiting, callers during a refresh wait for
+//it instead of sending another request and the previous keys are kept when the request fails
+func (k *KeySet) refresh() {
+	k.mutex.Lock()
+	if refreshing := k.refreshing; refreshing != nil {
+		k.mutex.Unlock()
+		<-refreshing
+		return
+	}
+
+	if time.Since(k.lastRefresh) < minimumRefreshWaiting {
+		k.mutex.Unlock()
+		return
+	}
+
+	refreshing := make(chan struct{})
+	k.refreshing = refreshing
+	k.lastRefresh = time.Now()
+	k.mutex.Unlock()
+
+	//the keys are fetched without holding the lock so requests with known keys are not blocked
+	keys, err := k.fetch()
+
+	k.mutex.Lock()
+	k.refreshing = nil
+	if err != nil {
+		log.Errorf("unable to load keys from %v: %v", k.url, err)
+	} else {
+		k.keys = keys
+		k.fetched = time.Now()
+		log.Debugf("loaded %v keys from %v", len(keys), k.url)
+	}
+
+	k.mutex.Unlock()
+	close(refreshing)
+}
+
+func (k *KeySet) fetch() (map[string]interface{}, error) {
+	resp, err := k.client.Get(k.url)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %v", resp.Status)
+	}
+
+	var jwks = Jwks{}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.PublicKey()
+		if err != nil {
+			log.Debugf("skipping key %v: %v", jwk.Kid, err)
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+//PublicKey returns the RSA or EC public key described by the json web key
+func (jwk *JSONWebKeys) PublicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		if jwk.N == "" || jwk.E == "" {
+			return jwk.certificateKey()
+		}
+
+		n, err := decodeKeyValue(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeKeyValue(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %v", jwk.Crv)
+		}
+
+		x, err := decodeKeyValue(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeKeyValue(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("invalid EC key")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %v", jwk.Kty)
+	}
+}
+
+func (jwk *JSONWebKeys) certificateKey() (interface{}, error) {
+	if len(jwk.X5c) == 0 {
+		return nil, fmt.Errorf("key has no modulus or certificate")
+	}
+
+	der, err := base64.StdEncoding.DecodeString(jwk.X5c[0])
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return certificate.PublicKey, nil
+}
+
+func decodeKeyValue(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
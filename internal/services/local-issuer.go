@@ -0,0 +1,171 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/evcraddock/goarticles/internal/configs"
+)
+
+const (
+	defaultLocalIssuer = "goarticles"
+	defaultTokenTTL    = time.Hour
+	minimumHMACKeySize = 32
+)
+
+//Errors returned when a token can not be issued
+var (
+	ErrInvalidClient   = errors.New("invalid client credentials")
+	ErrInvalidAudience = errors.New("unknown audience")
+)
+
+//LocalIssuer issues and validates tokens signed with a key from the configuration
+type LocalIssuer struct {
+	issuer     string
+	audience   string
+	method     jwt.SigningMethod
+	signingKey interface{}
+	verifyKey  interface{}
+	ttl        time.Duration
+	rolesClaim string
+	clients    []configs.ClientCredentials
+}
+
+//NewLocalIssuer create issuer signing tokens with an HS256 secret or RS256 private key
+func NewLocalIssuer(config configs.AuthenticationConfiguration) (*LocalIssuer, error) {
+	key := []byte(config.SigningKey)
+	if config.SigningKey == "" && config.SigningKeyFile != "" {
+		var err error
+		if key, err = ioutil.ReadFile(config.SigningKeyFile); err != nil {
+			return nil, fmt.Errorf("unable to read signing key: %v", err)
+		}
+	}
+
+	issuer := &LocalIssuer{
+		issuer:     config.Issuer,
+		audience:   config.Audience,
+		ttl:        config.TokenTTL,
+		rolesClaim: config.RolesClaim,
+		clients:    config.Clients,
+	}
+
+	if issuer.issuer == "" {
+		issuer.issuer = defaultLocalIssuer
+	}
+
+	if issuer.ttl <= 0 {
+		issuer.ttl = defaultTokenTTL
+	}
+
+	if issuer.rolesClaim == "" {
+		issuer.rolesClaim = defaultRolesClaim
+	}
+
+	switch strings.ToUpper(config.SigningMethod) {
+	case "", jwt.SigningMethodHS256.Alg():
+		if len(key) < minimumHMACKeySize {
+			return nil, fmt.Errorf("HS256 signing key must be at least %v bytes", minimumHMACKeySize)
+		}
+
+		issuer.method = jwt.SigningMethodHS256
+		issuer.signingKey = key
+		issuer.verifyKey = key
+	case jwt.SigningMethodRS256.Alg():
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse RS256 signing key: %v", err)
+		}
+
+		issuer.method = jwt.SigningMethodRS256
+		issuer.signingKey = privateKey
+		issuer.verifyKey = &privateKey.PublicKey
+	default:
+		return nil, fmt.Errorf("unsupported signing method: %v", config.SigningMethod)
+	}
+
+	return issuer, nil
+}
+
+//Issuer returns the issuer claim of local tokens
+func (l *LocalIssuer) Issuer() string {
+	return l.issuer
+}
+
+//SigningMethods returns the algorithm local tokens are signed with
+func (l *LocalIssuer) SigningMethods() []string {
+	return []string{l.method.Alg()}
+}
+
+//Key returns the key used to verify local tokens
+func (l *LocalIssuer) Key(token *jwt.Token) (interface{}, error) {
+	return l.verifyKey, nil
+}
+
+//IssueToken returns a signed token for the client and how long it is valid for
+func (l *LocalIssuer) IssueToken(clientID, clientSecret, audience string) (string, time.Duration, error) {
+	client := l.findClient(clientID, clientSecret)
+	if client == nil {
+		return "", 0, ErrInvalidClient
+	}
+
+	if audience != "" && audience != l.audience {
+		return "", 0, ErrInvalidAudience
+	}
+
+	return l.sign(client.ID, client.ID, client.Scopes, client.Roles)
+}
+
+//IssueUserToken returns a signed session token for a local user and how long it is valid for
+func (l *LocalIssuer) IssueUserToken(username string, roles []string) (string, time.Duration, error) {
+	return l.sign(username, "", RoleScopes(roles), roles)
+}
+
+func (l *LocalIssuer) sign(subject, clientID string, scopes, roles []string) (string, time.Duration, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": l.issuer,
+		"sub": subject,
+		"aud": l.audience,
+		"iat": now.Unix(),
+		"exp": now.Add(l.ttl).Unix(),
+	}
+
+	if clientID != "" {
+		claims["azp"] = clientID
+	}
+
+	if len(scopes) > 0 {
+		claims["scope"] = strings.Join(scopes, " ")
+	}
+
+	if len(roles) > 0 {
+		claims[l.rolesClaim] = roles
+	}
+
+	token, err := jwt.NewWithClaims(l.method, claims).SignedString(l.signingKey)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return token, l.ttl, nil
+}
+
+func (l *LocalIssuer) findClient(clientID, clientSecret string) *configs.ClientCredentials {
+	for i, client := range l.clients {
+		if client.ID == "" || client.Secret == "" || client.ID != clientID {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(client.Secret), []byte(clientSecret)) == 1 {
+			return &l.clients[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/evcraddock/goarticles/internal/metrics"
+	"github.com/evcraddock/goarticles/internal/utils"
+)
+
+//Scopes required by api routes, ScopeAdmin grants every scope
+const (
+	ScopeReadDrafts       = "read:drafts"
+	ScopeWriteArticles    = "write:articles"
+	ScopeDeleteArticles   = "delete:articles"
+	ScopeManageImages     = "manage:images"
+	ScopeModerateComments = "moderate:comments"
+	ScopeAdmin            = "admin"
+)
+
+//Scopes every scope that can be granted
+var Scopes = []string{
+	ScopeReadDrafts,
+	ScopeWriteArticles,
+	ScopeDeleteArticles,
+	ScopeManageImages,
+	ScopeModerateComments,
+	ScopeAdmin,
+}
+
+//Roles read from the roles claim
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleAuthor = "author"
+)
+
+//Roles every role that can be assigned to a local user
+var Roles = []string{RoleAdmin, RoleEditor, RoleAuthor}
+
+//roleScopes scopes granted to local users with each role
+var roleScopes = map[string][]string{
+	RoleAdmin:  {ScopeAdmin},
+	RoleEditor: {ScopeReadDrafts, ScopeWriteArticles, ScopeDeleteArticles, ScopeManageImages, ScopeModerateComments},
+	RoleAuthor: {ScopeReadDrafts, ScopeWriteArticles, ScopeManageImages},
+}
+
+const defaultRolesClaim = "roles"
+
+//RoleScopes returns the scopes granted by roles
+func RoleScopes(roles []string) []string {
+	scopes := make([]string, 0)
+	for _, role := range roles {
+		for _, scope := range roleScopes[role] {
+			if !utils.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return scopes
+}
+
+type principalContextKey struct{}
+
+//Principal identity and permissions of an authenticated request
+type Principal struct {
+	Subject  string
+	ClientID string
+	Scopes   []string
+	Roles    []string
+}
+
+//HasScope checks if the principal was granted scope or the admin scope
+func (p *Principal) HasScope(scope string) bool {
+	return utils.Contains(p.Scopes, scope) || utils.Contains(p.Scopes, ScopeAdmin)
+}
+
+//HasRole checks if the principal has role
+func (p *Principal) HasRole(role string) bool {
+	return utils.Contains(p.Roles, role)
+}
+
+//OwnArticlesOnly checks if the principal is an author that may only change their own articles
+func (p *Principal) OwnArticlesOnly() bool {
+	return p.HasRole(RoleAuthor) && !p.HasRole(RoleEditor) && !p.HasRole(RoleAdmin) && !utils.Contains(p.Scopes, ScopeAdmin)
+}
+
+//RequestPrincipal returns the principal of an authorized request, nil for anonymous requests
+func RequestPrincipal(r *http.Request) *Principal {
+	principal, _ := r.Context().Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+//withPrincipal returns a copy of the request carrying principal, the subject is added to the request's access log
+func withPrincipal(r *http.Request, principal *Principal) *http.Request {
+	if info := GetRequestInfo(r); info != nil {
+		info.User = principal.Subject
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), principalContextKey{}, principal))
+}
+
+//RequestHasScope checks if the request was sent by a principal that was granted scope
+func RequestHasScope(r *http.Request, scope string) bool {
+	principal := RequestPrincipal(r)
+	return principal != nil && principal.HasScope(scope)
+}
+
+//RequireScope wraps an authorized handler and rejects principals that were not granted scope
+func (auth *Authorization) RequireScope(scope string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := RequestPrincipal(r)
+		if principal == nil {
+			principal = &Principal{}
+		}
+
+		if scope != "" && !principal.HasScope(scope) {
+			RequestLog(r).Infof("%v is missing scope %v for %v %v", principal.Subject, scope, r.Method, r.URL.Path)
+			ForbiddenError(w, r, fmt.Sprintf("%v scope is required", scope))
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+//TokenPrincipal reads the subject, client, scopes and roles from the claims of a token
+func (auth *Authorization) TokenPrincipal(token *jwt.Token) *Principal {
+	principal := &Principal{}
+	if token == nil {
+		return principal
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return principal
+	}
+
+	principal.Subject, _ = claims["sub"].(string)
+	principal.ClientID, _ = claims["azp"].(string)
+	if principal.ClientID == "" {
+		principal.ClientID, _ = claims["client_id"].(string)
+	}
+
+	for _, claim := range []string{"scope", "scp", "permissions"} {
+		principal.Scopes = append(principal.Scopes, claimValues(claims[claim])...)
+	}
+
+	principal.Roles = claimValues(claims[auth.rolesClaim])
+
+	return principal
+}
+
+//ForbiddenError writes the error returned when a token is valid but lacks permission
+func ForbiddenError(w http.ResponseWriter, r *http.Request, message string) {
+	metrics.AuthFailure(metrics.AuthForbidden)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	apiError := NewError(fmt.Errorf("%v", message), message, "Forbidden", false)
+	apiError.RequestID = RequestID(r)
+
+	errorData, _ := json.Marshal(apiError)
+	w.WriteHeader(apiError.Status())
+	w.Write(errorData)
+}
+
+//claimValues returns the values of a space separated string or array claim
+func claimValues(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		scope     string
+		principal *Principal
+		status    int
+	}{
+		{"anonymous", ScopeWriteArticles, nil, http.StatusForbidden},
+		{"missing scope", ScopeDeleteArticles, &Principal{Scopes: []string{ScopeWriteArticles}}, http.StatusForbidden},
+		{"granted scope", ScopeWriteArticles, &Principal{Scopes: []string{ScopeWriteArticles}}, http.StatusOK},
+		{"admin scope", ScopeModerateComments, &Principal{Scopes: []string{ScopeAdmin}}, http.StatusOK},
+		{"role scopes", ScopeReadDrafts, &Principal{Scopes: RoleScopes([]string{RoleAuthor})}, http.StatusOK},
+		{"no scope required", "", nil, http.StatusOK},
+	}
+
+	auth := NewAuthorization(nil, "", "")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := auth.RequireScope(test.scope, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			r := httptest.NewRequest("GET", "/api/articles", nil)
+			if test.principal != nil {
+				r = withPrincipal(r, test.principal)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != test.status {
+				t.Fatalf("expected status %v, got %v: %v", test.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOwnArticlesOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		expected  bool
+	}{
+		{"author", Principal{Roles: []string{RoleAuthor}}, true},
+		{"author and editor", Principal{Roles: []string{RoleAuthor, RoleEditor}}, false},
+		{"author and admin", Principal{Roles: []string{RoleAuthor, RoleAdmin}}, false},
+		{"author with admin scope", Principal{Roles: []string{RoleAuthor}, Scopes: []string{ScopeAdmin}}, false},
+		{"no roles", Principal{Scopes: []string{ScopeWriteArticles}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.principal.OwnArticlesOnly(); actual != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
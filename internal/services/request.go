@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type requestInfoContextKey struct{}
+
+//RequestInfo details of a request collected for its access log
+type RequestInfo struct {
+	ID    string
+	Route string
+	User  string
+	IP    string
+}
+
+//WithRequestInfo returns a copy of the request carrying info
+func WithRequestInfo(r *http.Request, info *RequestInfo) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), requestInfoContextKey{}, info))
+}
+
+//GetRequestInfo returns the info of a request, nil when the request was not assigned an id
+func GetRequestInfo(r *http.Request) *RequestInfo {
+	info, _ := r.Context().Value(requestInfoContextKey{}).(*RequestInfo)
+	return info
+}
+
+//RequestID returns the id assigned to a request
+func RequestID(r *http.Request) string {
+	if info := GetRequestInfo(r); info != nil {
+		return info.ID
+	}
+
+	return ""
+}
+
+//RequestLog returns a logger that adds the request id to each entry
+func RequestLog(r *http.Request) *log.Entry {
+	return log.WithField("requestId", RequestID(r))
+}
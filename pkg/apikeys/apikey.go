@@ -0,0 +1,92 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	keyPrefix    = "goa_"
+	keyBytes     = 24
+	prefixLength = len(keyPrefix) + 8
+)
+
+//APIKey key issued to a machine client, only a hash of the key is stored
+type APIKey struct {
+	ID         bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	Hash       string        `json:"-"`
+	Key        string        `bson:"-" json:"key,omitempty"`
+	Scopes     []string      `json:"scopes"`
+	ExpiresAt  time.Time     `bson:"expiresat" json:"expiresAt"`
+	LastUsedAt time.Time     `bson:"lastusedat" json:"lastUsedAt"`
+	RevokedAt  time.Time     `bson:"revokedat" json:"revokedAt"`
+	CreatedAt  time.Time     `bson:"createdat" json:"createdAt"`
+}
+
+//APIKeys collection of api keys
+type APIKeys []APIKey
+
+//Generate creates a random key and returns it with its hash
+func Generate() (string, string, error) {
+	data := make([]byte, keyBytes)
+	if _, err := rand.Read(data); err != nil {
+		return "", "", err
+	}
+
+	key := keyPrefix + hex.EncodeToString(data)
+	return key, Hash(key), nil
+}
+
+//Hash returns the hash a key is stored and looked up by
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+//KeyPrefix returns the start of a key, used to identify it without revealing the key
+func KeyPrefix(key string) string {
+	if len(key) < prefixLength {
+		return key
+	}
+
+	return key[:prefixLength]
+}
+
+//IsKey checks if value has the format of a generated key
+func IsKey(value string) bool {
+	return strings.HasPrefix(value, keyPrefix) && len(value) == len(keyPrefix)+keyBytes*2
+}
+
+//ValidateAPIKey checks the api key can be saved
+func (key *APIKey) ValidateAPIKey() error {
+	if strings.TrimSpace(key.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if len(key.Scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+
+	if !key.ExpiresAt.IsZero() && !key.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expiresAt must be in the future")
+	}
+
+	return nil
+}
+
+//IsActive checks the key has not been revoked or expired
+func (key *APIKey) IsActive(now time.Time) bool {
+	if !key.RevokedAt.IsZero() {
+		return false
+	}
+
+	return key.ExpiresAt.IsZero() || now.Before(key.ExpiresAt)
+}
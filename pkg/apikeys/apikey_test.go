@@ -0,0 +1,65 @@
+package apikeys
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsKey(t *testing.T) {
+	key, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hash != Hash(key) {
+		t.Fatalf("expected hash %v, got %v", Hash(key), hash)
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected bool
+	}{
+		{"generated key", key, true},
+		{"missing prefix", strings.TrimPrefix(key, keyPrefix), false},
+		{"other prefix", "gob_" + strings.TrimPrefix(key, keyPrefix), false},
+		{"too short", key[:len(key)-1], false},
+		{"too long", key + "0", false},
+		{"bearer token", "eyJhbGciOiJIUzI1NiJ9.e30.signature", false},
+		{"empty", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := IsKey(test.value); actual != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsActive(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		key      APIKey
+		expected bool
+	}{
+		{"no expiry", APIKey{}, true},
+		{"expires later", APIKey{ExpiresAt: now.Add(time.Hour)}, true},
+		{"expired", APIKey{ExpiresAt: now.Add(-time.Hour)}, false},
+		{"expires now", APIKey{ExpiresAt: now}, false},
+		{"revoked", APIKey{RevokedAt: now.Add(-time.Hour)}, false},
+		{"revoked before expiry", APIKey{ExpiresAt: now.Add(time.Hour), RevokedAt: now.Add(-time.Hour)}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.key.IsActive(now); actual != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
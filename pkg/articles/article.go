@@ -8,20 +8,40 @@ import (
 	"fmt"
 
 	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/pkg/markdown"
 )
 
 //Article model
 type Article struct {
-	ID          bson.ObjectId `bson:"_id" json:"id"`
-	Title       string        `json:"title"`
-	Author      string        `json:"author"`
-	URL         string        `json:"url"`
-	Content     string        `json:"content"`
-	Banner      string        `json:"banner"`
-	DataSource  string        `json:"dataSource"`
-	PublishDate time.Time     `json:"publishDate"`
-	Categories  []string      `json:"categories"`
-	Tags        []string      `json:"tags"`
+	ID               bson.ObjectId      `bson:"_id" json:"id"`
+	Title            string             `json:"title"`
+	Author           string             `json:"author"`
+	URL              string             `json:"url"`
+	Content          string             `json:"content"`
+	Banner           string             `json:"banner"`
+	DataSource       string             `json:"dataSource"`
+	PublishDate      time.Time          `json:"publishDate"`
+	Categories       []string           `json:"categories"`
+	Tags             []string           `json:"tags"`
+	Series           string             `json:"series,omitempty"`
+	Summary          string             `json:"summary,omitempty"`
+	Excerpt          string             `json:"excerpt"`
+	WordCount        int                `json:"wordCount"`
+	ReadingTime      int                `json:"readingTime"`
+	TableOfContents  []markdown.Heading `json:"tableOfContents"`
+	MetaDescription  string             `json:"metaDescription,omitempty"`
+	CanonicalURL     string             `json:"canonicalUrl,omitempty"`
+	NoIndex          bool               `json:"noIndex"`
+	SocialImage      string             `json:"socialImage,omitempty"`
+	Layout           string             `json:"layout,omitempty"`
+	Language         string             `json:"language,omitempty"`
+	TranslationGroup string             `json:"translationGroup,omitempty"`
+	CreatedAt        time.Time          `bson:"createdat" json:"createdAt"`
+	UpdatedAt        time.Time          `bson:"updatedat" json:"updatedAt"`
+	Navigation       *Navigation        `bson:"-" json:"navigation,omitempty"`
+	CommentCount     int                `bson:"-" json:"commentCount"`
+	HTML             string             `bson:"-" json:"html,omitempty"`
 }
 
 //Articles collection of articles
@@ -59,6 +79,24 @@ func (article *Article) ValidateRequiredFields() []error {
 	return errors
 }
 
+//Link returns the absolute url of the article on the public site
+func (article *Article) Link(siteURL string) string {
+	if isAbsoluteURL(article.URL) {
+		return article.URL
+	}
+
+	return strings.TrimRight(siteURL, "/") + "/" + strings.TrimLeft(article.URL, "/")
+}
+
+//LastModified returns when the article was last updated, or published when that is later or it was never updated
+func (article *Article) LastModified() time.Time {
+	if article.UpdatedAt.After(article.PublishDate) {
+		return article.UpdatedAt
+	}
+
+	return article.PublishDate
+}
+
 //MarshalJSON custom MarshalJSON for articles
 func (article *Article) MarshalJSON() ([]byte, error) {
 	id := ""
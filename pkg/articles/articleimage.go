@@ -1,6 +1,9 @@
 package articles
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+	"strings"
+)
 
 //ArticleImage represents an image for an article
 type ArticleImage struct {
@@ -13,3 +16,17 @@ type ArticleImage struct {
 func (image *ArticleImage) GetPath() string {
 	return image.ArticleID + "/" + image.FileName
 }
+
+//ImageURL returns the absolute api url of an image belonging to the article
+func (article *Article) ImageURL(apiURL, filename string) string {
+	if isAbsoluteURL(filename) {
+		return filename
+	}
+
+	filename = strings.TrimPrefix(filename, "./")
+	return strings.TrimRight(apiURL, "/") + "/api/articles/" + article.ID.Hex() + "/images/" + filename
+}
+
+func isAbsoluteURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "//")
+}
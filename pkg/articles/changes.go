@@ -0,0 +1,38 @@
+package articles
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+//Tombstone records when an article was deleted
+type Tombstone struct {
+	ID        string    `bson:"_id" json:"id"`
+	DeletedAt time.Time `bson:"deletedat" json:"deletedAt"`
+}
+
+//Tombstones collection of tombstones
+type Tombstones []Tombstone
+
+//Changes articles saved and deleted since a sync token, Token is used to request the next changes
+type Changes struct {
+	Updated Articles   `json:"updated"`
+	Deleted Tombstones `json:"deleted"`
+	Token   string     `json:"token"`
+}
+
+//NewSyncToken creates the opaque token for changes up to t
+func NewSyncToken(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 36)
+}
+
+//ParseSyncToken returns the time encoded in a sync token
+func ParseSyncToken(token string) (time.Time, error) {
+	milliseconds, err := strconv.ParseInt(token, 36, 64)
+	if err != nil || milliseconds < 0 {
+		return time.Time{}, fmt.Errorf("invalid sync token: %v", token)
+	}
+
+	return time.Unix(0, milliseconds*int64(time.Millisecond)).UTC(), nil
+}
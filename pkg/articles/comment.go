@@ -0,0 +1,107 @@
+package articles
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const maxCommentLength = 5000
+
+//Comment moderation statuses
+const (
+	CommentPending  = "pending"
+	CommentApproved = "approved"
+	CommentSpam     = "spam"
+)
+
+//Comment model
+type Comment struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	ArticleID string        `bson:"articleid" json:"articleId"`
+	ParentID  string        `bson:"parentid,omitempty" json:"parentId,omitempty"`
+	Author    string        `json:"author"`
+	Content   string        `json:"content"`
+	Status    string        `json:"status"`
+	Verified  bool          `json:"verified"`
+	Subject   string        `json:"-"`
+	IPAddress string        `bson:"ipaddress" json:"-"`
+	CreatedAt time.Time     `bson:"createdat" json:"createdAt"`
+	Replies   Comments      `bson:"-" json:"replies,omitempty"`
+}
+
+//Comments collection of comments
+type Comments []Comment
+
+//ValidateComment checks the comment can be saved
+func (comment *Comment) ValidateComment() error {
+	comment.Content = strings.TrimSpace(comment.Content)
+	comment.Author = strings.TrimSpace(comment.Author)
+
+	if comment.Content == "" {
+		return fmt.Errorf("content is required")
+	}
+
+	if len(comment.Content) > maxCommentLength {
+		return fmt.Errorf("content must be %v characters or less", maxCommentLength)
+	}
+
+	if comment.ParentID != "" && !bson.IsObjectIdHex(comment.ParentID) {
+		return fmt.Errorf("invalid parent id: %v", comment.ParentID)
+	}
+
+	if comment.Author == "" {
+		comment.Author = "Anonymous"
+	}
+
+	return nil
+}
+
+//IsValidCommentStatus checks if status is a known moderation status
+func IsValidCommentStatus(status string) bool {
+	switch status {
+	case CommentPending, CommentApproved, CommentSpam:
+		return true
+	default:
+		return false
+	}
+}
+
+//Thread nests replies under their parent comments
+func (comments Comments) Thread() Comments {
+	children := make(map[string][]int)
+	roots := make([]int, 0)
+	ids := make(map[string]bool, len(comments))
+
+	for _, comment := range comments {
+		ids[comment.ID.Hex()] = true
+	}
+
+	for i, comment := range comments {
+		if comment.ParentID == "" || !ids[comment.ParentID] {
+			roots = append(roots, i)
+			continue
+		}
+
+		children[comment.ParentID] = append(children[comment.ParentID], i)
+	}
+
+	var build func(index int) Comment
+	build = func(index int) Comment {
+		comment := comments[index]
+		for _, child := range children[comment.ID.Hex()] {
+			comment.Replies = append(comment.Replies, build(child))
+		}
+
+		return comment
+	}
+
+	thread := make(Comments, 0, len(roots))
+	for _, root := range roots {
+		thread = append(thread, build(root))
+	}
+
+	return thread
+}
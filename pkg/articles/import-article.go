@@ -1,16 +1,106 @@
 package articles
 
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
 //ImportArticle represents and article that can be imported
 type ImportArticle struct {
-	ID          string   `yaml:"id"`
-	Title       string   `yaml:"title"`
-	URL         string   `yaml:"url"`
-	Banner      string   `yaml:"banner"`
-	Images      []string `yaml:"images"`
-	PublishDate string   `yaml:"publishDate"`
-	Author      string   `yaml:"author"`
-	Categories  []string `yaml:"categories"`
-	Tags        []string `yaml:"tags"`
-	Layout      string   `yaml:"layout"`
-	Content     string   `fm:"content" yaml:"-"`
+	ID               string   `yaml:"id"`
+	Title            string   `yaml:"title"`
+	URL              string   `yaml:"url"`
+	Banner           string   `yaml:"banner"`
+	Images           []string `yaml:"images"`
+	PublishDate      string   `yaml:"publishDate"`
+	Author           string   `yaml:"author"`
+	Categories       []string `yaml:"categories"`
+	Tags             []string `yaml:"tags"`
+	Series           string   `yaml:"series,omitempty"`
+	Summary          string   `yaml:"summary,omitempty"`
+	MetaDescription  string   `yaml:"metaDescription,omitempty"`
+	CanonicalURL     string   `yaml:"canonicalUrl,omitempty"`
+	NoIndex          bool     `yaml:"noindex,omitempty"`
+	SocialImage      string   `yaml:"socialImage,omitempty"`
+	Language         string   `yaml:"language,omitempty"`
+	TranslationGroup string   `yaml:"translationGroup,omitempty"`
+	Layout           string   `yaml:"layout"`
+	Content          string   `fm:"content" yaml:"-"`
+}
+
+//ImportDateFormat layout of the publishDate front matter field
+const ImportDateFormat = "01/02/2006"
+
+//NewImportArticle creates the front matter representation of an article
+func NewImportArticle(article *Article) *ImportArticle {
+	importArticle := &ImportArticle{
+		ID:               article.ID.Hex(),
+		Title:            article.Title,
+		URL:              article.URL,
+		Author:           article.Author,
+		Banner:           article.Banner,
+		Categories:       article.Categories,
+		Content:          article.Content,
+		Tags:             article.Tags,
+		Series:           article.Series,
+		Summary:          article.Summary,
+		MetaDescription:  article.MetaDescription,
+		CanonicalURL:     article.CanonicalURL,
+		NoIndex:          article.NoIndex,
+		SocialImage:      article.SocialImage,
+		Layout:           article.Layout,
+		Language:         article.Language,
+		TranslationGroup: article.TranslationGroup,
+	}
+
+	if !article.PublishDate.IsZero() {
+		importArticle.PublishDate = article.PublishDate.Format(ImportDateFormat)
+	}
+
+	return importArticle
+}
+
+//ToArticle converts the front matter representation back into an article, an empty publishDate leaves it unpublished
+func (importArticle *ImportArticle) ToArticle() (*Article, error) {
+	article := &Article{
+		Title:            importArticle.Title,
+		URL:              importArticle.URL,
+		Author:           importArticle.Author,
+		Banner:           importArticle.Banner,
+		Categories:       importArticle.Categories,
+		Content:          importArticle.Content,
+		Tags:             importArticle.Tags,
+		Series:           importArticle.Series,
+		Summary:          importArticle.Summary,
+		MetaDescription:  importArticle.MetaDescription,
+		CanonicalURL:     importArticle.CanonicalURL,
+		NoIndex:          importArticle.NoIndex,
+		SocialImage:      importArticle.SocialImage,
+		Layout:           importArticle.Layout,
+		Language:         importArticle.Language,
+		TranslationGroup: importArticle.TranslationGroup,
+	}
+
+	if importArticle.ID != "" {
+		if !bson.IsObjectIdHex(importArticle.ID) {
+			return nil, fmt.Errorf("invalid article id: %v", importArticle.ID)
+		}
+
+		article.ID = bson.ObjectIdHex(importArticle.ID)
+	}
+
+	if importArticle.PublishDate == "" {
+		return article, nil
+	}
+
+	publishDate, err := time.Parse(ImportDateFormat, importArticle.PublishDate)
+	if err != nil {
+		return nil, err
+	}
+
+	article.PublishDate = publishDate
+
+	return article, nil
 }
@@ -0,0 +1,45 @@
+package articles
+
+import (
+	"strings"
+
+	"github.com/evcraddock/goarticles/pkg/markdown"
+)
+
+const (
+	wordsPerMinute = 200
+	excerptWords   = 55
+)
+
+//SetReadingMetadata computes word count, reading time, excerpt and table of contents from the content
+func (article *Article) SetReadingMetadata() {
+	metadata := markdown.Analyze(article.Content)
+
+	article.WordCount = metadata.WordCount
+	article.ReadingTime = 0
+	if metadata.WordCount > 0 {
+		article.ReadingTime = (metadata.WordCount + wordsPerMinute - 1) / wordsPerMinute
+	}
+
+	article.TableOfContents = metadata.Headings
+	article.Summary = strings.TrimSpace(article.Summary)
+	article.Excerpt = article.Summary
+	if article.Excerpt == "" {
+		article.Excerpt = excerpt(metadata.Paragraphs, excerptWords)
+	}
+}
+
+func excerpt(paragraphs []string, limit int) string {
+	words := make([]string, 0, limit)
+	for _, paragraph := range paragraphs {
+		for _, word := range strings.Fields(paragraph) {
+			if len(words) == limit {
+				return strings.Join(words, " ") + "…"
+			}
+
+			words = append(words, word)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,159 @@
+package articles
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	tagWeight      = 0.4
+	categoryWeight = 0.2
+	contentWeight  = 0.4
+)
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "any": true, "can": true, "has": true, "have": true,
+	"was": true, "were": true, "this": true, "that": true, "with": true, "from": true,
+	"they": true, "will": true, "would": true, "there": true, "their": true, "what": true,
+	"when": true, "which": true, "your": true, "into": true, "than": true, "then": true,
+	"them": true, "these": true, "those": true, "been": true, "also": true, "its": true,
+}
+
+//IsPublished checks if the article has a publish date that has already passed
+func (article *Article) IsPublished() bool {
+	return !article.PublishDate.IsZero() && !article.PublishDate.After(time.Now())
+}
+
+//RelatedArticles ranks candidates by shared tags, shared categories and content similarity to article
+func RelatedArticles(article Article, candidates Articles, limit int) Articles {
+	corpus := make([]map[string]float64, len(candidates))
+	documentFrequency := make(map[string]int)
+
+	target := termFrequency(article.Content)
+	for term := range target {
+		documentFrequency[term]++
+	}
+
+	for i, candidate := range candidates {
+		corpus[i] = termFrequency(candidate.Content)
+		for term := range corpus[i] {
+			documentFrequency[term]++
+		}
+	}
+
+	documents := len(candidates) + 1
+	targetVector := tfidf(target, documentFrequency, documents)
+
+	type scoredArticle struct {
+		article Article
+		score   float64
+	}
+
+	scored := make([]scoredArticle, 0, len(candidates))
+	for i, candidate := range candidates {
+		if candidate.ID == article.ID || !candidate.IsPublished() {
+			continue
+		}
+
+		score := tagWeight*overlap(article.Tags, candidate.Tags) +
+			categoryWeight*overlap(article.Categories, candidate.Categories) +
+			contentWeight*cosineSimilarity(targetVector, tfidf(corpus[i], documentFrequency, documents))
+
+		if score > 0 {
+			scored = append(scored, scoredArticle{article: candidate, score: score})
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	results := make(Articles, len(scored))
+	for i, s := range scored {
+		results[i] = s.article
+	}
+
+	return results
+}
+
+func termFrequency(content string) map[string]float64 {
+	words := strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	frequency := make(map[string]float64)
+	total := 0
+	for _, word := range words {
+		if len(word) < 3 || stopWords[word] {
+			continue
+		}
+
+		frequency[word]++
+		total++
+	}
+
+	for term := range frequency {
+		frequency[term] = frequency[term] / float64(total)
+	}
+
+	return frequency
+}
+
+func tfidf(frequency map[string]float64, documentFrequency map[string]int, documents int) map[string]float64 {
+	vector := make(map[string]float64, len(frequency))
+	for term, tf := range frequency {
+		vector[term] = tf * math.Log(float64(documents)/float64(documentFrequency[term]))
+	}
+
+	return vector
+}
+
+func cosineSimilarity(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, value := range a {
+		dot += value * b[term]
+		normA += value * value
+	}
+
+	for _, value := range b {
+		normB += value * value
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func overlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	values := make(map[string]bool, len(a))
+	for _, v := range a {
+		values[strings.ToLower(v)] = true
+	}
+
+	shared := 0
+	union := len(values)
+	for _, v := range b {
+		if values[strings.ToLower(v)] {
+			shared++
+			continue
+		}
+
+		union++
+	}
+
+	return float64(shared) / float64(union)
+}
@@ -0,0 +1,111 @@
+package articles
+
+import "time"
+
+//MetaTag html meta tag for the head of an article page
+type MetaTag struct {
+	Property string `json:"property,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Content  string `json:"content"`
+}
+
+//PageMetadata ready made seo, Open Graph and Twitter Card metadata for an article page
+type PageMetadata struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Canonical   string    `json:"canonical"`
+	Robots      string    `json:"robots"`
+	Image       string    `json:"image,omitempty"`
+	Tags        []MetaTag `json:"tags"`
+}
+
+//Description returns the meta description, falling back to the summary and excerpt
+func (article *Article) Description() string {
+	for _, description := range []string{article.MetaDescription, article.Summary, article.Excerpt} {
+		if description != "" {
+			return description
+		}
+	}
+
+	return ""
+}
+
+//CanonicalLink returns the canonical url, falling back to the article link on the public site
+func (article *Article) CanonicalLink(siteURL string) string {
+	if article.CanonicalURL != "" {
+		return article.CanonicalURL
+	}
+
+	return article.Link(siteURL)
+}
+
+//SocialImageURL returns the absolute url of the social image, falling back to the banner
+func (article *Article) SocialImageURL(apiURL string) string {
+	if article.SocialImage != "" {
+		return article.ImageURL(apiURL, article.SocialImage)
+	}
+
+	if article.Banner != "" {
+		return article.ImageURL(apiURL, article.Banner)
+	}
+
+	return ""
+}
+
+//GetPageMetadata builds the metadata for an article page
+func (article *Article) GetPageMetadata(siteName, siteURL, apiURL string) PageMetadata {
+	metadata := PageMetadata{
+		Title:       article.Title,
+		Description: article.Description(),
+		Canonical:   article.CanonicalLink(siteURL),
+		Robots:      "index, follow",
+		Image:       article.SocialImageURL(apiURL),
+	}
+
+	if article.NoIndex {
+		metadata.Robots = "noindex, nofollow"
+	}
+
+	card := "summary"
+	if metadata.Image != "" {
+		card = "summary_large_image"
+	}
+
+	tags := []MetaTag{
+		{Name: "description", Content: metadata.Description},
+		{Name: "robots", Content: metadata.Robots},
+		{Property: "og:type", Content: "article"},
+		{Property: "og:title", Content: metadata.Title},
+		{Property: "og:description", Content: metadata.Description},
+		{Property: "og:url", Content: metadata.Canonical},
+		{Property: "og:site_name", Content: siteName},
+		{Name: "twitter:card", Content: card},
+		{Name: "twitter:title", Content: metadata.Title},
+		{Name: "twitter:description", Content: metadata.Description},
+	}
+
+	if metadata.Image != "" {
+		tags = append(tags,
+			MetaTag{Property: "og:image", Content: metadata.Image},
+			MetaTag{Name: "twitter:image", Content: metadata.Image})
+	}
+
+	if !article.PublishDate.IsZero() {
+		tags = append(tags, MetaTag{Property: "article:published_time", Content: article.PublishDate.UTC().Format(time.RFC3339)})
+	}
+
+	if article.Author != "" {
+		tags = append(tags, MetaTag{Property: "article:author", Content: article.Author})
+	}
+
+	for _, category := range article.Categories {
+		tags = append(tags, MetaTag{Property: "article:section", Content: category})
+	}
+
+	for _, tag := range article.Tags {
+		tags = append(tags, MetaTag{Property: "article:tag", Content: tag})
+	}
+
+	metadata.Tags = tags
+	return metadata
+}
@@ -0,0 +1,65 @@
+package articles
+
+import "gopkg.in/mgo.v2/bson"
+
+//Series groups articles into an ordered collection
+type Series struct {
+	ID          bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	Title       string        `json:"title"`
+	Slug        string        `json:"slug"`
+	Description string        `json:"description"`
+	Articles    []string      `json:"articles"`
+}
+
+//SeriesList collection of series
+type SeriesList []Series
+
+//Navigation links to the articles surrounding an article
+type Navigation struct {
+	Series   *SeriesNavigation `json:"series,omitempty"`
+	Previous *ArticleLink      `json:"previous,omitempty"`
+	Next     *ArticleLink      `json:"next,omitempty"`
+}
+
+//SeriesNavigation links to the surrounding articles within a series
+type SeriesNavigation struct {
+	Slug     string       `json:"slug"`
+	Title    string       `json:"title"`
+	Position int          `json:"position"`
+	Total    int          `json:"total"`
+	Previous *ArticleLink `json:"previous,omitempty"`
+	Next     *ArticleLink `json:"next,omitempty"`
+}
+
+//ArticleLink reference to another article
+type ArticleLink struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Href  string `json:"href"`
+}
+
+//NewArticleLink creates a link to article
+func NewArticleLink(article *Article) *ArticleLink {
+	if article == nil {
+		return nil
+	}
+
+	return &ArticleLink{
+		ID:    article.ID.Hex(),
+		Title: article.Title,
+		URL:   article.URL,
+		Href:  "/api/articles/" + article.ID.Hex(),
+	}
+}
+
+//IndexOf returns the position of the article id in the series or -1 if it is not found
+func (series *Series) IndexOf(id string) int {
+	for i, articleID := range series.Articles {
+		if articleID == id {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,40 @@
+package articles
+
+import (
+	"golang.org/x/text/language"
+)
+
+//SelectTranslation returns the candidate that best matches the accepted languages, or the first candidate when none match
+func SelectTranslation(candidates Articles, acceptLanguage string) *Article {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	accepted, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(accepted) == 0 {
+		return &candidates[0]
+	}
+
+	tags := make([]language.Tag, 0, len(candidates))
+	indexes := make([]int, 0, len(candidates))
+	for i, candidate := range candidates {
+		tag, err := language.Parse(candidate.Language)
+		if err != nil {
+			continue
+		}
+
+		tags = append(tags, tag)
+		indexes = append(indexes, i)
+	}
+
+	if len(tags) == 0 {
+		return &candidates[0]
+	}
+
+	_, index, confidence := language.NewMatcher(tags).Match(accepted...)
+	if confidence == language.No {
+		return &candidates[0]
+	}
+
+	return &candidates[indexes[index]]
+}
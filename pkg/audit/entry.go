@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+//Entry records an authenticated change made through the api
+type Entry struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	Subject   string        `json:"subject"`
+	ClientID  string        `bson:"clientid" json:"clientId,omitempty"`
+	Method    string        `json:"method"`
+	Route     string        `json:"route"`
+	Path      string        `json:"path"`
+	ArticleID string        `bson:"articleid" json:"articleId,omitempty"`
+	Status    int           `json:"status"`
+	Before    string        `json:"before,omitempty"`
+	After     string        `json:"after,omitempty"`
+	RequestID string        `bson:"requestid" json:"requestId,omitempty"`
+	CreatedAt time.Time     `bson:"createdat" json:"createdAt"`
+}
+
+//Entries collection of audit entries
+type Entries []Entry
+
+//Hash returns the hex encoded SHA-256 hash of the json representation of value
+func Hash(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
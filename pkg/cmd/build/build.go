@@ -0,0 +1,90 @@
+package build
+
+import (
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/evcraddock/goarticles/internal/cli"
+	"github.com/evcraddock/goarticles/internal/configs"
+)
+
+type BuildOptions struct {
+	Out  io.Writer
+	Site cli.SiteOptions
+}
+
+func newBuildOptions(out io.Writer) *BuildOptions {
+	return &BuildOptions{
+		Out: out,
+	}
+}
+
+func NewCmdBuild(out io.Writer) *cobra.Command {
+	o := newBuildOptions(out)
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "build a static site from articles",
+		Run: func(cmd *cobra.Command, args []string) {
+			o.prepare(cmd)
+			o.validate()
+			o.build()
+		},
+	}
+
+	cmd.Flags().String("configfile", "", "yaml configuration file, used to find the api when no source is specified (optional)")
+	cmd.Flags().String("source", "", "folder of front matter files to build from instead of the api")
+	cmd.Flags().String("templates", "templates", "folder of html templates")
+	cmd.Flags().String("output", "public", "folder to write the site to")
+	cmd.Flags().String("title", "", "site title")
+	cmd.Flags().String("description", "", "site description")
+	cmd.Flags().String("url", "", "public url of the site")
+	cmd.Flags().Bool("drafts", false, "include unpublished articles")
+	return cmd
+}
+
+func (o *BuildOptions) prepare(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	o.Site.Source, _ = flags.GetString("source")
+	o.Site.Templates, _ = flags.GetString("templates")
+	o.Site.Output, _ = flags.GetString("output")
+	o.Site.Title, _ = flags.GetString("title")
+	o.Site.Description, _ = flags.GetString("description")
+	o.Site.URL, _ = flags.GetString("url")
+	o.Site.Drafts, _ = flags.GetBool("drafts")
+
+	if o.Site.Source != "" {
+		return
+	}
+
+	if configFile, err := flags.GetString("configfile"); err == nil {
+		config, err := configs.LoadCliConfig(configFile)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		o.Site.APIURL = config.URL
+	}
+}
+
+func (o *BuildOptions) validate() {
+	if o.Site.Templates == "" || o.Site.Output == "" {
+		log.Fatalf("templates and output folders are required")
+	}
+
+	if o.Site.Source == "" && o.Site.APIURL == "" {
+		log.Fatalf("either a source folder or an api url is required")
+	}
+}
+
+func (o *BuildOptions) build() {
+	log.SetFormatter(&log.JSONFormatter{})
+	log.SetOutput(o.Out)
+	log.SetLevel(log.InfoLevel)
+
+	builder := cli.NewSiteBuilder(o.Site)
+	if err := builder.Build(); err != nil {
+		log.Fatal(err.Error())
+	}
+}
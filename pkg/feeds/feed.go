@@ -0,0 +1,103 @@
+package feeds
+
+import (
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/evcraddock/goarticles/pkg/articles"
+	"github.com/evcraddock/goarticles/pkg/markdown"
+)
+
+//Site describes the public site a feed is generated for
+type Site struct {
+	Title       string
+	Description string
+	URL         string
+	APIURL      string
+}
+
+//Feed format independent feed
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	FeedURL     string
+	Updated     time.Time
+	Items       []Item
+}
+
+//Item format independent feed entry
+type Item struct {
+	ID         string
+	Title      string
+	Link       string
+	Author     string
+	Summary    string
+	Content    string
+	Image      string
+	Published  time.Time
+	Categories []string
+	Tags       []string
+}
+
+//NewFeed creates a feed from articles, rendering the full content when fullContent is set
+func NewFeed(site Site, title, feedURL string, results articles.Articles, fullContent bool) (*Feed, error) {
+	feed := &Feed{
+		Title:       title,
+		Description: site.Description,
+		Link:        strings.TrimRight(site.URL, "/") + "/",
+		FeedURL:     feedURL,
+		Items:       make([]Item, 0, len(results)),
+	}
+
+	for i := range results {
+		article := &results[i]
+		item := Item{
+			ID:         article.ID.Hex(),
+			Title:      article.Title,
+			Link:       article.Link(site.URL),
+			Author:     article.Author,
+			Summary:    article.Excerpt,
+			Published:  article.PublishDate,
+			Categories: article.Categories,
+			Tags:       article.Tags,
+		}
+
+		if item.Summary == "" {
+			item.Summary = article.Summary
+		}
+
+		if article.Banner != "" {
+			item.Image = article.ImageURL(site.APIURL, article.Banner)
+		}
+
+		if fullContent {
+			html, err := markdown.Render(article.Content)
+			if err != nil {
+				return nil, err
+			}
+
+			item.Content = markdown.RewriteImages(html, func(src string) string {
+				return article.ImageURL(site.APIURL, src)
+			})
+		}
+
+		if article.LastModified().After(feed.Updated) {
+			feed.Updated = article.LastModified()
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed, nil
+}
+
+func imageType(url string) string {
+	if contentType := mime.TypeByExtension(path.Ext(url)); contentType != "" {
+		return contentType
+	}
+
+	return "image/jpeg"
+}
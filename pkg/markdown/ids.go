@@ -0,0 +1,59 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+)
+
+//headingIDs generates heading ids that keep the letters and numbers of every script, the default generator drops
+//everything that is not ASCII
+type headingIDs struct {
+	values map[string]bool
+}
+
+func newHeadingIDs() parser.IDs {
+	return &headingIDs{values: make(map[string]bool)}
+}
+
+//Generate returns a unique id for the text of a heading
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var builder strings.Builder
+	for _, r := range strings.TrimSpace(string(value)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			builder.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			builder.WriteRune('-')
+		}
+	}
+
+	id := builder.String()
+	if id == "" {
+		id = "id"
+		if kind == ast.KindHeading {
+			id = "heading"
+		}
+	}
+
+	result := id
+	for i := 1; s.values[result]; i++ {
+		result = fmt.Sprintf("%v-%v", id, i)
+	}
+
+	s.values[result] = true
+	return []byte(result)
+}
+
+//Put records an id set explicitly so generated ids do not repeat it
+func (s *headingIDs) Put(value []byte) {
+	s.values[string(value)] = true
+}
+
+//parseContext returns the context of a single document, heading ids are unique within it
+func parseContext() parser.ParseOption {
+	return parser.WithContext(parser.NewContext(parser.WithIDs(newHeadingIDs())))
+}
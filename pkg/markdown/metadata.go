@@ -0,0 +1,91 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+//Heading entry in a table of contents
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
+//Metadata values computed from markdown content
+type Metadata struct {
+	WordCount  int
+	Headings   []Heading
+	Paragraphs []string
+}
+
+//Analyze walks the markdown document counting words and collecting headings and paragraphs
+func Analyze(source string) Metadata {
+	src := []byte(source)
+	document := converter.Parser().Parse(text.NewReader(src), parseContext())
+	metadata := Metadata{}
+
+	//text is split into several nodes at emphasis, links and line breaks, so words are counted once all the text
+	//has been collected, blocks are separated so words in different blocks are not joined
+	var words strings.Builder
+	ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		if node.Type() == ast.TypeBlock {
+			words.WriteString(" ")
+		}
+
+		switch n := node.(type) {
+		case *ast.Heading:
+			heading := Heading{
+				Level: n.Level,
+				Text:  string(n.Text(src)),
+			}
+
+			if id, found := n.AttributeString("id"); found {
+				if value, ok := id.([]byte); ok {
+					heading.ID = string(value)
+				}
+			}
+
+			metadata.Headings = append(metadata.Headings, heading)
+		case *ast.Paragraph:
+			metadata.Paragraphs = append(metadata.Paragraphs, plainText(n, src))
+		case *ast.Text:
+			words.Write(n.Segment.Value(src))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				words.WriteString(" ")
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	metadata.WordCount = len(strings.Fields(words.String()))
+	return metadata
+}
+
+func plainText(node ast.Node, source []byte) string {
+	var builder strings.Builder
+
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		if t, ok := n.(*ast.Text); ok {
+			builder.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				builder.WriteString(" ")
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(builder.String())
+}
@@ -0,0 +1,147 @@
+package repos
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/apikeys"
+)
+
+//APIKeyRepository model
+type APIKeyRepository struct {
+	Server       string
+	DatabaseName string
+}
+
+//CreateAPIKeyRepository creates a new repository
+func CreateAPIKeyRepository(server, databaseName string) *APIKeyRepository {
+	return &APIKeyRepository{
+		Server:       server,
+		DatabaseName: databaseName,
+	}
+}
+
+//GetAPIKeys returns all api keys, newest first
+func (r *APIKeyRepository) GetAPIKeys() (*apikeys.APIKeys, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	results := apikeys.APIKeys{}
+	if err := services.NewError(
+		session.DB(r.DatabaseName).C("apikeys").Find(nil).Sort("-createdat").All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//GetAPIKeyByHash returns the api key with hash
+func (r *APIKeyRepository) GetAPIKeyByHash(hash string) (*apikeys.APIKey, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	result := apikeys.APIKey{}
+	if err := session.DB(r.DatabaseName).C("apikeys").Find(bson.M{"hash": hash}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, services.NewError(err, "api key doesn't exist", "NotFound", false)
+		}
+
+		return nil, services.NewError(err, "error retrieving data", "DatabaseError", false)
+	}
+
+	return &result, nil
+}
+
+//AddAPIKey add api key to database
+func (r *APIKeyRepository) AddAPIKey(key apikeys.APIKey) (*apikeys.APIKey, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("apikeys")
+	if err := c.EnsureIndex(mgo.Index{Key: []string{"hash"}, Unique: true}); err != nil {
+		return nil, services.NewError(err, "failed to create api key index", "DatabaseError", false)
+	}
+
+	key.ID = bson.NewObjectId()
+	key.CreatedAt = time.Now().UTC()
+	if err := services.NewError(
+		c.Insert(key),
+		"failed to create api key",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	log.Debug("Added API Key ID: ", key.ID)
+
+	return &key, nil
+}
+
+//RevokeAPIKey marks api key as revoked, revoked keys are kept so they still appear in the audit log
+func (r *APIKeyRepository) RevokeAPIKey(id string) (*apikeys.APIKey, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, services.NewError(fmt.Errorf("invalid id"), "can not find record: invalid id", "NotFound", false)
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	result := apikeys.APIKey{}
+	change := mgo.Change{
+		Update:    bson.M{"$set": bson.M{"revokedat": time.Now().UTC()}},
+		ReturnNew: true,
+	}
+
+	query := bson.M{"_id": bson.ObjectIdHex(id), "revokedat": time.Time{}}
+	if _, err := session.DB(r.DatabaseName).C("apikeys").Find(query).Apply(change, &result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, services.NewError(err, "api key does not exist or is already revoked", "NotFound", false)
+		}
+
+		return nil, services.NewError(err, "failed to revoke api key", "DatabaseError", false)
+	}
+
+	log.Debug("Revoked API Key ID: ", id)
+
+	return &result, nil
+}
+
+//UpdateLastUsed records when the api key was last used
+func (r *APIKeyRepository) UpdateLastUsed(id bson.ObjectId, lastUsed time.Time) error {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	if err := session.DB(r.DatabaseName).C("apikeys").UpdateId(id, bson.M{"$set": bson.M{"lastusedat": lastUsed}}); err != nil {
+		return services.NewError(err, "failed to update api key", "DatabaseError", false)
+	}
+
+	return nil
+}
@@ -1,6 +1,11 @@
 package repos
 
 import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
@@ -8,7 +13,9 @@ import (
 
 	"fmt"
 
+	"github.com/evcraddock/goarticles/internal/metrics"
 	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/internal/tracing"
 	"github.com/evcraddock/goarticles/pkg/articles"
 )
 
@@ -30,7 +37,9 @@ func CreateArticleRepository(server, databaseName string) *ArticleRepository {
 }
 
 //GetArticles returns queried articles from database
-func (r *ArticleRepository) GetArticles(query map[string]interface{}) (*articles.Articles, error) {
+func (r *ArticleRepository) GetArticles(ctx context.Context, query map[string]interface{}) (*articles.Articles, error) {
+	defer startOperation(ctx, "GetArticles")()
+
 	log.Debugf("Connecting to database %v", r.Server)
 	session, err := mgo.Dial(r.Server)
 	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
@@ -52,8 +61,76 @@ func (r *ArticleRepository) GetArticles(query map[string]interface{}) (*articles
 	return &results, nil
 }
 
+//CountArticles returns the number of articles matching query
+func (r *ArticleRepository) CountArticles(ctx context.Context, query map[string]interface{}) (int, error) {
+	defer startOperation(ctx, "CountArticles")()
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return 0, err
+	}
+
+	defer session.Close()
+
+	count, err := session.DB(r.DatabaseName).C("articles").Find(query).Count()
+	if err != nil {
+		return 0, services.NewError(err, "error retrieving data", "DatabaseError", false)
+	}
+
+	return count, nil
+}
+
+//GetArticlesPage returns a page of queried articles in a stable order
+func (r *ArticleRepository) GetArticlesPage(ctx context.Context, query map[string]interface{}, skip, limit int) (*articles.Articles, error) {
+	defer startOperation(ctx, "GetArticlesPage")()
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("articles")
+	results := articles.Articles{}
+	if err := services.NewError(
+		c.Find(query).Sort("publishdate", "_id").Skip(skip).Limit(limit).All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//GetRecentArticles returns the limit most recently published articles matching query, newest first
+func (r *ArticleRepository) GetRecentArticles(ctx context.Context, query map[string]interface{}, limit int) (*articles.Articles, error) {
+	defer startOperation(ctx, "GetRecentArticles")()
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	results := articles.Articles{}
+	if err := services.NewError(
+		session.DB(r.DatabaseName).C("articles").Find(query).Sort("-publishdate", "-_id").Limit(limit).All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
 //GetArticle returns article by Id
-func (r *ArticleRepository) GetArticle(id string) (*articles.Article, error) {
+func (r *ArticleRepository) GetArticle(ctx context.Context, id string) (*articles.Article, error) {
+	defer startOperation(ctx, "GetArticle")()
+
 	session, err := mgo.Dial(r.Server)
 	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
 		return nil, err
@@ -79,8 +156,70 @@ func (r *ArticleRepository) GetArticle(id string) (*articles.Article, error) {
 	return &result, nil
 }
 
+//FindArticle returns the first article matching query
+func (r *ArticleRepository) FindArticle(ctx context.Context, query map[string]interface{}) (*articles.Article, error) {
+	defer startOperation(ctx, "FindArticle")()
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("articles")
+	result := articles.Article{}
+	if err := services.NewError(
+		c.Find(query).One(&result),
+		"article doesn't exist",
+		"NotFound",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+//GetAdjacentArticles returns the published articles immediately before and after a published article by publish
+//date, articles published at the same time are ordered by id
+func (r *ArticleRepository) GetAdjacentArticles(ctx context.Context, article articles.Article) (*articles.Article, *articles.Article, error) {
+	defer startOperation(ctx, "GetAdjacentArticles")()
+
+	if !article.IsPublished() {
+		return nil, nil, nil
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("articles")
+	previous, err := r.findAdjacent(c, bson.M{"$or": []bson.M{
+		{"publishdate": bson.M{"$gt": time.Time{}, "$lt": article.PublishDate}},
+		{"publishdate": article.PublishDate, "_id": bson.M{"$lt": article.ID}},
+	}}, "-publishdate", "-_id")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next, err := r.findAdjacent(c, bson.M{"$or": []bson.M{
+		{"publishdate": bson.M{"$gt": article.PublishDate, "$lte": time.Now()}},
+		{"publishdate": article.PublishDate, "_id": bson.M{"$gt": article.ID}},
+	}}, "publishdate", "_id")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return previous, next, nil
+}
+
 //AddArticle add article to database
-func (r *ArticleRepository) AddArticle(article articles.Article) (*articles.Article, error) {
+func (r *ArticleRepository) AddArticle(ctx context.Context, article articles.Article) (*articles.Article, error) {
+	defer startOperation(ctx, "AddArticle")()
+
 	session, err := mgo.Dial(r.Server)
 	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
 		return nil, err
@@ -89,6 +228,8 @@ func (r *ArticleRepository) AddArticle(article articles.Article) (*articles.Arti
 	defer session.Close()
 
 	article.ID = bson.NewObjectId()
+	article.CreatedAt = now()
+	article.UpdatedAt = article.CreatedAt
 	if err := services.NewError(
 		session.DB(r.DatabaseName).C("articles").Insert(article),
 		"failed to create article",
@@ -103,7 +244,9 @@ func (r *ArticleRepository) AddArticle(article articles.Article) (*articles.Arti
 }
 
 //UpdateArticle updates article
-func (r *ArticleRepository) UpdateArticle(article articles.Article) (*articles.Article, error) {
+func (r *ArticleRepository) UpdateArticle(ctx context.Context, article articles.Article) (*articles.Article, error) {
+	defer startOperation(ctx, "UpdateArticle")()
+
 	session, err := mgo.Dial(r.Server)
 	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
 		return nil, err
@@ -116,6 +259,22 @@ func (r *ArticleRepository) UpdateArticle(article articles.Article) (*articles.A
 		return nil, err
 	}
 
+	existing := articles.Article{}
+	if err := services.NewError(
+		c.FindId(oid).Select(bson.M{"createdat": 1}).One(&existing),
+		"could not find article",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	//articles saved before timestamps were tracked use the creation time of their id
+	article.CreatedAt = existing.CreatedAt
+	if article.CreatedAt.IsZero() {
+		article.CreatedAt = oid.Time().UTC()
+	}
+
+	article.UpdatedAt = now()
 	if err := services.NewError(
 		c.UpdateId(oid, article),
 		"failed to update article",
@@ -130,7 +289,9 @@ func (r *ArticleRepository) UpdateArticle(article articles.Article) (*articles.A
 }
 
 //DeleteArticle deletes article
-func (r *ArticleRepository) DeleteArticle(id string) error {
+func (r *ArticleRepository) DeleteArticle(ctx context.Context, id string) error {
+	defer startOperation(ctx, "DeleteArticle")()
+
 	session, err := mgo.Dial(r.Server)
 	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
 		return err
@@ -147,13 +308,65 @@ func (r *ArticleRepository) DeleteArticle(id string) error {
 		return services.NewError(err, "failed to delete article", "DatabaseError", false)
 	}
 
+	tombstone := articles.Tombstone{ID: oid.Hex(), DeletedAt: now()}
+	if _, err := session.DB(r.DatabaseName).C("tombstones").UpsertId(tombstone.ID, tombstone); err != nil {
+		return services.NewError(err, "failed to record deleted article", "DatabaseError", false)
+	}
+
 	log.Debug("Delete Article ID: ", oid)
 
 	return nil
 }
 
+//GetChanges returns articles saved and deleted after since and up to until, a zero since returns every article
+func (r *ArticleRepository) GetChanges(ctx context.Context, since, until time.Time) (*articles.Changes, error) {
+	defer startOperation(ctx, "GetChanges")()
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	changes := &articles.Changes{
+		Updated: articles.Articles{},
+		Deleted: articles.Tombstones{},
+	}
+
+	updated := bson.M{"updatedat": bson.M{"$not": bson.M{"$gt": until}}}
+	if !since.IsZero() {
+		updated = bson.M{"updatedat": bson.M{"$gt": since, "$lte": until}}
+	}
+
+	if err := services.NewError(
+		session.DB(r.DatabaseName).C("articles").Find(updated).Sort("updatedat").All(&changes.Updated),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	if since.IsZero() {
+		return changes, nil
+	}
+
+	deleted := bson.M{"deletedat": bson.M{"$gt": since, "$lte": until}}
+	if err := services.NewError(
+		session.DB(r.DatabaseName).C("tombstones").Find(deleted).Sort("deletedat").All(&changes.Deleted),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
 //ArticleExists check to see if artcle exists in database
-func (r *ArticleRepository) ArticleExists(id string) (bool, error) {
+func (r *ArticleRepository) ArticleExists(ctx context.Context, id string) (bool, error) {
+	defer startOperation(ctx, "ArticleExists")()
+
 	session, err := mgo.Dial(r.Server)
 	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
 		return false, err
@@ -168,6 +381,20 @@ func (r *ArticleRepository) ArticleExists(id string) (bool, error) {
 	return true, nil
 }
 
+func (r *ArticleRepository) findAdjacent(collection *mgo.Collection, query bson.M, sort ...string) (*articles.Article, error) {
+	result := articles.Article{}
+	err := collection.Find(query).Sort(sort...).One(&result)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, services.NewError(err, "error retrieving data", "DatabaseError", false)
+	}
+
+	return &result, nil
+}
+
 func (r *ArticleRepository) articleExists(collection *mgo.Collection, id string) (*bson.ObjectId, error) {
 	if !bson.IsObjectIdHex(id) {
 		err := services.NewError(fmt.Errorf("invalid id"), "can not find record: invalid id", "NotFound", false)
@@ -186,3 +413,24 @@ func (r *ArticleRepository) articleExists(collection *mgo.Collection, id string)
 
 	return &oid, nil
 }
+
+//startOperation times and traces an article repository operation, the returned function ends both
+func startOperation(ctx context.Context, operation string) func() {
+	start := time.Now()
+	_, span := tracing.Start(ctx, "ArticleRepository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.operation", operation),
+		))
+
+	return func() {
+		span.End()
+		metrics.ObserveDatabase(operation, start)
+	}
+}
+
+//now returns the current time at the millisecond precision stored by the database
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,68 @@
+package repos
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/audit"
+)
+
+//AuditRepository model
+type AuditRepository struct {
+	Server       string
+	DatabaseName string
+}
+
+//CreateAuditRepository creates a new repository
+func CreateAuditRepository(server, databaseName string) *AuditRepository {
+	return &AuditRepository{
+		Server:       server,
+		DatabaseName: databaseName,
+	}
+}
+
+//AddEntry add audit entry to database
+func (r *AuditRepository) AddEntry(entry audit.Entry) error {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	entry.ID = bson.NewObjectId()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+
+	if err := session.DB(r.DatabaseName).C("audit").Insert(entry); err != nil {
+		return services.NewError(err, "failed to create audit entry", "DatabaseError", false)
+	}
+
+	return nil
+}
+
+//GetEntries returns the most recent queried audit entries
+func (r *AuditRepository) GetEntries(query map[string]interface{}, limit int) (*audit.Entries, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("audit")
+	results := audit.Entries{}
+	if err := services.NewError(
+		c.Find(query).Sort("-createdat").Limit(limit).All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
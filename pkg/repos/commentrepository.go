@@ -0,0 +1,227 @@
+package repos
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/articles"
+)
+
+//CommentRepository model
+type CommentRepository struct {
+	Server       string
+	DatabaseName string
+}
+
+//CreateCommentRepository creates a new repository
+func CreateCommentRepository(server, databaseName string) *CommentRepository {
+	return &CommentRepository{
+		Server:       server,
+		DatabaseName: databaseName,
+	}
+}
+
+//GetComments returns queried comments ordered by creation date
+func (r *CommentRepository) GetComments(query map[string]interface{}) (*articles.Comments, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("comments")
+	results := articles.Comments{}
+	if err := services.NewError(
+		c.Find(query).Sort("createdat").All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//GetComment returns comment by Id
+func (r *CommentRepository) GetComment(id string) (*articles.Comment, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, services.NewError(fmt.Errorf("invalid id"), "can not find record: invalid id", "NotFound", false)
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("comments")
+	result := articles.Comment{}
+	if err := services.NewError(
+		c.FindId(bson.ObjectIdHex(id)).One(&result),
+		"comment doesn't exist",
+		"NotFound",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+//AddComment add comment to database
+func (r *CommentRepository) AddComment(comment articles.Comment) (*articles.Comment, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	comment.ID = bson.NewObjectId()
+	if err := services.NewError(
+		session.DB(r.DatabaseName).C("comments").Insert(comment),
+		"failed to create comment",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	log.Debug("Added Comment ID: ", comment.ID)
+
+	return &comment, nil
+}
+
+//UpdateCommentStatus sets the moderation status of a comment
+func (r *CommentRepository) UpdateCommentStatus(id, status string) error {
+	if !bson.IsObjectIdHex(id) {
+		return services.NewError(fmt.Errorf("invalid id"), "can not find record: invalid id", "NotFound", false)
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("comments")
+	if err := c.UpdateId(bson.ObjectIdHex(id), bson.M{"$set": bson.M{"status": status}}); err != nil {
+		if err == mgo.ErrNotFound {
+			return services.NewError(err, "comment does not exist", "NotFound", false)
+		}
+
+		return services.NewError(err, "failed to update comment", "DatabaseError", false)
+	}
+
+	log.Debugf("Updated Comment ID: %v status: %v", id, status)
+
+	return nil
+}
+
+//DeleteComment deletes comment and every reply in its thread
+func (r *CommentRepository) DeleteComment(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return services.NewError(fmt.Errorf("invalid id"), "can not find record: invalid id", "NotFound", false)
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("comments")
+	if err := c.RemoveId(bson.ObjectIdHex(id)); err != nil {
+		if err == mgo.ErrNotFound {
+			return services.NewError(err, "comment does not exist", "NotFound", false)
+		}
+
+		return services.NewError(err, "failed to delete comment", "DatabaseError", false)
+	}
+
+	//replies are found one level at a time so replies to replies are removed as well
+	parents := []string{id}
+	for len(parents) > 0 {
+		var replies []articles.Comment
+		if err := c.Find(bson.M{"parentid": bson.M{"$in": parents}}).Select(bson.M{"_id": 1}).All(&replies); err != nil {
+			return services.NewError(err, "failed to find comment replies", "DatabaseError", false)
+		}
+
+		ids := make([]bson.ObjectId, 0, len(replies))
+		parents = make([]string, 0, len(replies))
+		for _, reply := range replies {
+			ids = append(ids, reply.ID)
+			parents = append(parents, reply.ID.Hex())
+		}
+
+		if len(ids) == 0 {
+			break
+		}
+
+		if _, err := c.RemoveAll(bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return services.NewError(err, "failed to delete comment replies", "DatabaseError", false)
+		}
+	}
+
+	log.Debug("Deleted Comment ID: ", id)
+
+	return nil
+}
+
+//DeleteArticleComments deletes every comment for an article
+func (r *CommentRepository) DeleteArticleComments(articleID string) error {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	if _, err := session.DB(r.DatabaseName).C("comments").RemoveAll(bson.M{"articleid": articleID}); err != nil {
+		return services.NewError(err, "failed to delete comments", "DatabaseError", false)
+	}
+
+	return nil
+}
+
+//CountApprovedComments returns the number of approved comments for each article id
+func (r *CommentRepository) CountApprovedComments(articleIDs []string) (map[string]int, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"articleid": bson.M{"$in": articleIDs}, "status": articles.CommentApproved}},
+		{"$group": bson.M{"_id": "$articleid", "count": bson.M{"$sum": 1}}},
+	}
+
+	var results []struct {
+		ArticleID string `bson:"_id"`
+		Count     int    `bson:"count"`
+	}
+
+	c := session.DB(r.DatabaseName).C("comments")
+	if err := services.NewError(
+		c.Pipe(pipeline).All(&results),
+		"error retrieving comment counts",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		counts[result.ArticleID] = result.Count
+	}
+
+	return counts, nil
+}
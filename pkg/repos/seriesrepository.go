@@ -0,0 +1,160 @@
+package repos
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/articles"
+)
+
+//SeriesRepository model
+type SeriesRepository struct {
+	Server       string
+	DatabaseName string
+}
+
+//CreateSeriesRepository creates a new repository
+func CreateSeriesRepository(server, databaseName string) *SeriesRepository {
+	return &SeriesRepository{
+		Server:       server,
+		DatabaseName: databaseName,
+	}
+}
+
+//GetAllSeries returns every series from database
+func (r *SeriesRepository) GetAllSeries() (*articles.SeriesList, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("series")
+	results := articles.SeriesList{}
+	if err := services.NewError(
+		c.Find(nil).Sort("title").All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//GetSeries returns series by slug
+func (r *SeriesRepository) GetSeries(slug string) (*articles.Series, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("series")
+	result := articles.Series{}
+	if err := services.NewError(
+		c.Find(bson.M{"slug": slug}).One(&result),
+		"series doesn't exist",
+		"NotFound",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+//AddSeries add series to database
+func (r *SeriesRepository) AddSeries(series articles.Series) (*articles.Series, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("series")
+	count, err := c.Find(bson.M{"slug": series.Slug}).Count()
+	if err != nil {
+		return nil, services.NewError(err, "could not find series", "DatabaseError", false)
+	}
+
+	if count > 0 {
+		err := fmt.Errorf("series already exists: %v", series.Slug)
+		return nil, services.NewError(err, "series slug must be unique", "ValidationError", false)
+	}
+
+	series.ID = bson.NewObjectId()
+	if err := services.NewError(
+		c.Insert(series),
+		"failed to create series",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	log.Debug("Added Series: ", series.Slug)
+
+	return &series, nil
+}
+
+//UpdateSeries updates series matching slug
+func (r *SeriesRepository) UpdateSeries(slug string, series articles.Series) (*articles.Series, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("series")
+	existing := articles.Series{}
+	if err := services.NewError(
+		c.Find(bson.M{"slug": slug}).One(&existing),
+		"series does not exist",
+		"NotFound",
+		false); err != nil {
+		return nil, err
+	}
+
+	series.ID = existing.ID
+	if err := services.NewError(
+		c.UpdateId(existing.ID, series),
+		"failed to update series",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	log.Debug("Updated Series: ", series.Slug)
+
+	return &series, nil
+}
+
+//DeleteSeries deletes series by slug
+func (r *SeriesRepository) DeleteSeries(slug string) error {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("series")
+	if err := c.Remove(bson.M{"slug": slug}); err != nil {
+		if err == mgo.ErrNotFound {
+			return services.NewError(err, "series does not exist", "NotFound", false)
+		}
+
+		return services.NewError(err, "failed to delete series", "DatabaseError", false)
+	}
+
+	log.Debug("Deleted Series: ", slug)
+
+	return nil
+}
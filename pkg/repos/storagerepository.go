@@ -5,11 +5,16 @@ import (
 	"io"
 	"io/ioutil"
 	"mime/multipart"
+	"time"
 
 	"cloud.google.com/go/storage"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/evcraddock/goarticles/internal/metrics"
 	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/internal/tracing"
 )
 
 //StorageRepository represents a google cloud storage account
@@ -51,12 +56,17 @@ func (store *StorageRepository) createBucket(ctx context.Context, bucketName str
 
 //AddImage adds image to bucket for an article
 func (store *StorageRepository) AddImage(ctx context.Context, image string, file multipart.File) error {
+	ctx, end := startStorageOperation(ctx, "AddImage")
+	defer end()
+
 	bucket := store.client.Bucket(store.bucketName)
 	imgfile := bucket.Object(image)
 
 	ww := imgfile.NewWriter(ctx)
 
-	if _, err := io.Copy(ww, file); err != nil {
+	written, err := io.Copy(ww, file)
+	metrics.AddStorageBytes("AddImage", written)
+	if err != nil {
 		log.Error(err)
 		return services.NewError(err, "unable to write image to bucket", "StorageError", false)
 	}
@@ -71,6 +81,9 @@ func (store *StorageRepository) AddImage(ctx context.Context, image string, file
 
 //GetImage get image from storage and return as byte array
 func (store *StorageRepository) GetImage(ctx context.Context, image string) ([]byte, error) {
+	ctx, end := startStorageOperation(ctx, "GetImage")
+	defer end()
+
 	bucket := store.client.Bucket(store.bucketName)
 	imgreader, err := bucket.Object(image).NewReader(ctx)
 
@@ -81,6 +94,7 @@ func (store *StorageRepository) GetImage(ctx context.Context, image string) ([]b
 	defer imgreader.Close()
 
 	imgdata, err := ioutil.ReadAll(imgreader)
+	metrics.AddStorageBytes("GetImage", int64(len(imgdata)))
 	if err != nil {
 		return nil, services.NewError(err, "could not open file", "StorageError", false)
 	}
@@ -90,6 +104,9 @@ func (store *StorageRepository) GetImage(ctx context.Context, image string) ([]b
 
 //DeleteImage delete requested image
 func (store *StorageRepository) DeleteImage(ctx context.Context, image string) error {
+	ctx, end := startStorageOperation(ctx, "DeleteImage")
+	defer end()
+
 	bucket := store.client.Bucket(store.bucketName)
 	img := bucket.Object(image)
 	if err := img.Delete(ctx); err != nil {
@@ -98,3 +115,16 @@ func (store *StorageRepository) DeleteImage(ctx context.Context, image string) e
 
 	return nil
 }
+
+//startStorageOperation times and traces a storage operation, the returned function ends both
+func startStorageOperation(ctx context.Context, operation string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "StorageRepository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("storage.operation", operation)))
+
+	return ctx, func() {
+		span.End()
+		metrics.ObserveStorage(operation, start)
+	}
+}
@@ -0,0 +1,202 @@
+package repos
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/users"
+)
+
+//UserRepository model
+type UserRepository struct {
+	Server       string
+	DatabaseName string
+}
+
+//CreateUserRepository creates a new repository
+func CreateUserRepository(server, databaseName string) *UserRepository {
+	return &UserRepository{
+		Server:       server,
+		DatabaseName: databaseName,
+	}
+}
+
+//GetUsers returns all users
+func (r *UserRepository) GetUsers() (*users.Users, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	results := users.Users{}
+	if err := services.NewError(
+		session.DB(r.DatabaseName).C("users").Find(nil).Sort("username").All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//GetUserByUsername returns user by username
+func (r *UserRepository) GetUserByUsername(username string) (*users.User, error) {
+	return r.findUser(bson.M{"username": username})
+}
+
+//GetUser returns user by Id
+func (r *UserRepository) GetUser(id bson.ObjectId) (*users.User, error) {
+	return r.findUser(bson.M{"_id": id})
+}
+
+//AddUser add user to database
+func (r *UserRepository) AddUser(user users.User) (*users.User, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("users")
+	if err := c.EnsureIndex(mgo.Index{Key: []string{"username"}, Unique: true}); err != nil {
+		return nil, services.NewError(err, "failed to create user index", "DatabaseError", false)
+	}
+
+	user.ID = bson.NewObjectId()
+	user.CreatedAt = time.Now().UTC()
+	user.UpdatedAt = user.CreatedAt
+	if err := c.Insert(user); err != nil {
+		if mgo.IsDup(err) {
+			return nil, services.NewError(err, "username already exists", "ValidationError", false)
+		}
+
+		return nil, services.NewError(err, "failed to create user", "DatabaseError", false)
+	}
+
+	log.Debug("Added User ID: ", user.ID)
+
+	return &user, nil
+}
+
+//UpdatePassword saves a new password hash and revokes the refresh tokens of the user
+func (r *UserRepository) UpdatePassword(user users.User) error {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	update := bson.M{"$set": bson.M{"passwordhash": user.PasswordHash, "updatedat": time.Now().UTC()}}
+	if err := session.DB(r.DatabaseName).C("users").UpdateId(user.ID, update); err != nil {
+		if err == mgo.ErrNotFound {
+			return services.NewError(err, "user does not exist", "NotFound", false)
+		}
+
+		return services.NewError(err, "failed to update user", "DatabaseError", false)
+	}
+
+	if _, err := session.DB(r.DatabaseName).C("refreshtokens").RemoveAll(bson.M{"userid": user.ID}); err != nil {
+		return services.NewError(err, "failed to revoke refresh tokens", "DatabaseError", false)
+	}
+
+	log.Debug("Updated password of User ID: ", user.ID)
+
+	return nil
+}
+
+//DeleteUser deletes user and its refresh tokens
+func (r *UserRepository) DeleteUser(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return services.NewError(fmt.Errorf("invalid id"), "can not find record: invalid id", "NotFound", false)
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	if err := session.DB(r.DatabaseName).C("users").RemoveId(bson.ObjectIdHex(id)); err != nil {
+		if err == mgo.ErrNotFound {
+			return services.NewError(err, "user does not exist", "NotFound", false)
+		}
+
+		return services.NewError(err, "failed to delete user", "DatabaseError", false)
+	}
+
+	if _, err := session.DB(r.DatabaseName).C("refreshtokens").RemoveAll(bson.M{"userid": bson.ObjectIdHex(id)}); err != nil {
+		return services.NewError(err, "failed to revoke refresh tokens", "DatabaseError", false)
+	}
+
+	log.Debug("Deleted User ID: ", id)
+
+	return nil
+}
+
+//AddRefreshToken stores a refresh token
+func (r *UserRepository) AddRefreshToken(token users.RefreshToken) error {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	if err := session.DB(r.DatabaseName).C("refreshtokens").Insert(token); err != nil {
+		return services.NewError(err, "failed to create refresh token", "DatabaseError", false)
+	}
+
+	return nil
+}
+
+//TakeRefreshToken removes and returns the refresh token with hash so it can only be used once
+func (r *UserRepository) TakeRefreshToken(hash string) (*users.RefreshToken, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	result := users.RefreshToken{}
+	if _, err := session.DB(r.DatabaseName).C("refreshtokens").FindId(hash).Apply(mgo.Change{Remove: true}, &result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, services.NewError(err, "refresh token doesn't exist", "NotFound", false)
+		}
+
+		return nil, services.NewError(err, "error retrieving data", "DatabaseError", false)
+	}
+
+	return &result, nil
+}
+
+func (r *UserRepository) findUser(query bson.M) (*users.User, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	result := users.User{}
+	if err := session.DB(r.DatabaseName).C("users").Find(query).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, services.NewError(err, "user doesn't exist", "NotFound", false)
+		}
+
+		return nil, services.NewError(err, "error retrieving data", "DatabaseError", false)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,239 @@
+package repos
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/evcraddock/goarticles/internal/services"
+	"github.com/evcraddock/goarticles/pkg/webhooks"
+)
+
+//WebhookRepository model
+type WebhookRepository struct {
+	Server       string
+	DatabaseName string
+}
+
+//CreateWebhookRepository creates a new repository
+func CreateWebhookRepository(server, databaseName string) *WebhookRepository {
+	return &WebhookRepository{
+		Server:       server,
+		DatabaseName: databaseName,
+	}
+}
+
+//GetWebhooks returns queried webhooks
+func (r *WebhookRepository) GetWebhooks(query map[string]interface{}) (*webhooks.Webhooks, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("webhooks")
+	results := webhooks.Webhooks{}
+	if err := services.NewError(
+		c.Find(query).Sort("createdat").All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//GetWebhook returns webhook by Id
+func (r *WebhookRepository) GetWebhook(id string) (*webhooks.Webhook, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, services.NewError(fmt.Errorf("invalid id"), "can not find record: invalid id", "NotFound", false)
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("webhooks")
+	result := webhooks.Webhook{}
+	if err := services.NewError(
+		c.FindId(bson.ObjectIdHex(id)).One(&result),
+		"webhook doesn't exist",
+		"NotFound",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+//AddWebhook add webhook to database
+func (r *WebhookRepository) AddWebhook(webhook webhooks.Webhook) (*webhooks.Webhook, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	webhook.ID = bson.NewObjectId()
+	webhook.CreatedAt = time.Now().UTC()
+	if err := services.NewError(
+		session.DB(r.DatabaseName).C("webhooks").Insert(webhook),
+		"failed to create webhook",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	log.Debug("Added Webhook ID: ", webhook.ID)
+
+	return &webhook, nil
+}
+
+//UpdateWebhook updates webhook
+func (r *WebhookRepository) UpdateWebhook(webhook webhooks.Webhook) (*webhooks.Webhook, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("webhooks")
+	if err := c.UpdateId(webhook.ID, webhook); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, services.NewError(err, "webhook does not exist", "NotFound", false)
+		}
+
+		return nil, services.NewError(err, "failed to update webhook", "DatabaseError", false)
+	}
+
+	log.Debug("Updated Webhook ID: ", webhook.ID)
+
+	return &webhook, nil
+}
+
+//DeleteWebhook deletes webhook and its delivery log
+func (r *WebhookRepository) DeleteWebhook(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return services.NewError(fmt.Errorf("invalid id"), "can not find record: invalid id", "NotFound", false)
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	if err := session.DB(r.DatabaseName).C("webhooks").RemoveId(bson.ObjectIdHex(id)); err != nil {
+		if err == mgo.ErrNotFound {
+			return services.NewError(err, "webhook does not exist", "NotFound", false)
+		}
+
+		return services.NewError(err, "failed to delete webhook", "DatabaseError", false)
+	}
+
+	if _, err := session.DB(r.DatabaseName).C("webhookdeliveries").RemoveAll(bson.M{"webhookid": id}); err != nil {
+		return services.NewError(err, "failed to delete webhook deliveries", "DatabaseError", false)
+	}
+
+	log.Debug("Deleted Webhook ID: ", id)
+
+	return nil
+}
+
+//AddDeliveries adds deliveries to the delivery log
+func (r *WebhookRepository) AddDeliveries(deliveries webhooks.Deliveries) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	documents := make([]interface{}, len(deliveries))
+	for i := range deliveries {
+		deliveries[i].ID = bson.NewObjectId()
+		documents[i] = deliveries[i]
+	}
+
+	if err := session.DB(r.DatabaseName).C("webhookdeliveries").Insert(documents...); err != nil {
+		return services.NewError(err, "failed to create webhook deliveries", "DatabaseError", false)
+	}
+
+	return nil
+}
+
+//UpdateDelivery saves the result of a delivery attempt
+func (r *WebhookRepository) UpdateDelivery(delivery webhooks.Delivery) error {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	if err := session.DB(r.DatabaseName).C("webhookdeliveries").UpdateId(delivery.ID, delivery); err != nil {
+		return services.NewError(err, "failed to update webhook delivery", "DatabaseError", false)
+	}
+
+	return nil
+}
+
+//GetDeliveries returns the most recent deliveries for a webhook
+func (r *WebhookRepository) GetDeliveries(webhookID string, limit int) (*webhooks.Deliveries, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("webhookdeliveries")
+	results := webhooks.Deliveries{}
+	if err := services.NewError(
+		c.Find(bson.M{"webhookid": webhookID}).Sort("-createdat").Limit(limit).All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//GetDueDeliveries returns pending deliveries whose next attempt is before now
+func (r *WebhookRepository) GetDueDeliveries(now time.Time, limit int) (*webhooks.Deliveries, error) {
+	session, err := mgo.Dial(r.Server)
+	if err := services.NewError(err, "failed to establish connection to database", "DatabaseConnection", false); err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	c := session.DB(r.DatabaseName).C("webhookdeliveries")
+	results := webhooks.Deliveries{}
+	query := bson.M{"status": webhooks.DeliveryPending, "nextattempt": bson.M{"$lte": now}}
+	if err := services.NewError(
+		c.Find(query).Sort("nextattempt").Limit(limit).All(&results),
+		"error retrieving data",
+		"DatabaseError",
+		false); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
@@ -0,0 +1,101 @@
+package sitemaps
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+//MaxURLs maximum number of urls allowed in a single sitemap
+const MaxURLs = 50000
+
+type sitemapIndex struct {
+	XMLName  xml.Name  `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemap `xml:"sitemap"`
+}
+
+type sitemap struct {
+	Location     string `xml:"loc"`
+	LastModified string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	ImageNS string   `xml:"xmlns:image,attr"`
+	URLs    []url    `xml:"url"`
+}
+
+type url struct {
+	Location     string  `xml:"loc"`
+	LastModified string  `xml:"lastmod,omitempty"`
+	Images       []image `xml:"image:image"`
+}
+
+type image struct {
+	Location string `xml:"image:loc"`
+}
+
+//Entry url included in a sitemap
+type Entry struct {
+	Location     string
+	LastModified time.Time
+	Images       []string
+}
+
+//Page reference to a sitemap in the index
+type Page struct {
+	Location     string
+	LastModified time.Time
+}
+
+//NewIndex renders a sitemap index referencing pages
+func NewIndex(pages []Page) ([]byte, error) {
+	index := sitemapIndex{Sitemaps: make([]sitemap, 0, len(pages))}
+	for _, page := range pages {
+		index.Sitemaps = append(index.Sitemaps, sitemap{
+			Location:     page.Location,
+			LastModified: formatDate(page.LastModified),
+		})
+	}
+
+	return marshalXML(index)
+}
+
+//NewSitemap renders a sitemap with the image extension for entries with images
+func NewSitemap(entries []Entry) ([]byte, error) {
+	set := urlSet{
+		ImageNS: "http://www.google.com/schemas/sitemap-image/1.1",
+		URLs:    make([]url, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		u := url{
+			Location:     entry.Location,
+			LastModified: formatDate(entry.LastModified),
+		}
+
+		for _, location := range entry.Images {
+			u.Images = append(u.Images, image{Location: location})
+		}
+
+		set.URLs = append(set.URLs, u)
+	}
+
+	return marshalXML(set)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
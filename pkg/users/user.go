@@ -0,0 +1,110 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	minimumPasswordLength = 10
+	refreshTokenBytes     = 32
+
+	//unknownUserHash bcrypt hash with the default cost checked for usernames that do not exist
+	unknownUserHash = "$2a$10$O0U0wvxn44aoWpXV2ZYU1e6c28c68Xo1j0a7A76trnuDw.OUgBt3."
+)
+
+//User local account that can log in with a password
+type User struct {
+	ID           bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	Username     string        `json:"username"`
+	PasswordHash string        `bson:"passwordhash" json:"-"`
+	Roles        []string      `json:"roles"`
+	CreatedAt    time.Time     `bson:"createdat" json:"createdAt"`
+	UpdatedAt    time.Time     `bson:"updatedat" json:"updatedAt"`
+}
+
+//Users collection of users
+type Users []User
+
+//RefreshToken token exchanged for a new session, only a hash of the token is stored
+type RefreshToken struct {
+	ID        string        `bson:"_id"`
+	UserID    bson.ObjectId `bson:"userid"`
+	ExpiresAt time.Time     `bson:"expiresat"`
+	CreatedAt time.Time     `bson:"createdat"`
+}
+
+//NewUser creates a user with a hashed password
+func NewUser(username, password string, roles []string) (*User, error) {
+	user := &User{
+		Username: strings.TrimSpace(username),
+		Roles:    roles,
+	}
+
+	if user.Username == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+
+	if err := user.SetPassword(password); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+//SetPassword replaces the password hash of the user
+func (user *User) SetPassword(password string) error {
+	if len(password) < minimumPasswordLength {
+		return fmt.Errorf("password must be at least %v characters", minimumPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = string(hash)
+	return nil
+}
+
+//CheckPassword checks if password matches the hash of the user
+func (user *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+}
+
+//CheckUnknownUser checks password against a fixed hash so logins for usernames that do not exist take as long as
+//logins for users that do
+func CheckUnknownUser(password string) {
+	bcrypt.CompareHashAndPassword([]byte(unknownUserHash), []byte(password))
+}
+
+//NewRefreshToken creates a refresh token for the user and returns it with the record to store
+func NewRefreshToken(userID bson.ObjectId, ttl time.Duration) (string, *RefreshToken, error) {
+	data := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(data); err != nil {
+		return "", nil, err
+	}
+
+	token := hex.EncodeToString(data)
+	now := time.Now().UTC()
+
+	return token, &RefreshToken{
+		ID:        HashToken(token),
+		UserID:    userID,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+//HashToken returns the hash a refresh token is stored and looked up by
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
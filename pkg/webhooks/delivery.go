@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+//Delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+const (
+	//MaxAttempts number of times a delivery is sent before it is marked as failed
+	MaxAttempts = 8
+
+	initialBackoff = 30 * time.Second
+)
+
+//Delivery records an event sent to a webhook and the result of the last attempt
+type Delivery struct {
+	ID          bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	WebhookID   string        `bson:"webhookid" json:"webhookId"`
+	EventID     string        `bson:"eventid" json:"eventId"`
+	Event       string        `json:"event"`
+	Payload     string        `json:"payload"`
+	Status      string        `json:"status"`
+	Attempts    int           `json:"attempts"`
+	StatusCode  int           `bson:"statuscode" json:"statusCode,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	NextAttempt time.Time     `bson:"nextattempt" json:"nextAttempt"`
+	CreatedAt   time.Time     `bson:"createdat" json:"createdAt"`
+	UpdatedAt   time.Time     `bson:"updatedat" json:"updatedAt"`
+}
+
+//Deliveries collection of deliveries
+type Deliveries []Delivery
+
+//Backoff returns the delay before the next attempt, doubling after every failed attempt
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+
+	return initialBackoff << uint(attempts-1)
+}
+
+//Failed records a failed attempt and schedules a retry until MaxAttempts is reached
+func (delivery *Delivery) Failed(statusCode int, message string) {
+	delivery.Attempts++
+	delivery.StatusCode = statusCode
+	delivery.Error = message
+	delivery.UpdatedAt = time.Now().UTC()
+
+	if delivery.Attempts >= MaxAttempts {
+		delivery.Status = DeliveryFailed
+		return
+	}
+
+	delivery.Status = DeliveryPending
+	delivery.NextAttempt = delivery.UpdatedAt.Add(Backoff(delivery.Attempts))
+}
+
+//Succeeded records a successful attempt
+func (delivery *Delivery) Succeeded(statusCode int) {
+	delivery.Attempts++
+	delivery.StatusCode = statusCode
+	delivery.Error = ""
+	delivery.Status = DeliverySucceeded
+	delivery.UpdatedAt = time.Now().UTC()
+}
+
+//Abandon marks the delivery as failed without further retries
+func (delivery *Delivery) Abandon(message string) {
+	delivery.Error = message
+	delivery.Status = DeliveryFailed
+	delivery.UpdatedAt = time.Now().UTC()
+}
@@ -0,0 +1,135 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+//Event types a webhook can subscribe to
+const (
+	ArticleCreated   = "article.created"
+	ArticleUpdated   = "article.updated"
+	ArticleDeleted   = "article.deleted"
+	ArticlePublished = "article.published"
+	ImageUploaded    = "image.uploaded"
+	ImageDeleted     = "image.deleted"
+)
+
+//EventTypes every event type that is sent to webhooks
+var EventTypes = []string{
+	ArticleCreated,
+	ArticleUpdated,
+	ArticleDeleted,
+	ArticlePublished,
+	ImageUploaded,
+	ImageDeleted,
+}
+
+//Webhook subscription that receives events at URL
+type Webhook struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	URL       string        `json:"url"`
+	Secret    string        `json:"secret,omitempty"`
+	Events    []string      `json:"events"`
+	Active    bool          `json:"active"`
+	CreatedAt time.Time     `bson:"createdat" json:"createdAt"`
+}
+
+//Webhooks collection of webhooks
+type Webhooks []Webhook
+
+//Event payload sent to webhooks
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+//ImageEvent data sent with image events
+type ImageEvent struct {
+	ArticleID string `json:"articleId"`
+	FileName  string `json:"filename"`
+	Published bool   `json:"-"`
+}
+
+//NewEvent creates an event with a unique id
+func NewEvent(eventType string, data interface{}) Event {
+	return Event{
+		ID:        bson.NewObjectId().Hex(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+//ValidateWebhook checks the webhook can be saved
+func (webhook *Webhook) ValidateWebhook() error {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https url")
+	}
+
+	for _, event := range webhook.Events {
+		if !IsValidEventType(event) {
+			return fmt.Errorf("unknown event: %v", event)
+		}
+	}
+
+	return nil
+}
+
+//Subscribes checks if the webhook wants events of eventType, no events means every event
+func (webhook *Webhook) Subscribes(eventType string) bool {
+	if !webhook.Active {
+		return false
+	}
+
+	if len(webhook.Events) == 0 {
+		return true
+	}
+
+	for _, event := range webhook.Events {
+		if event == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+//IsValidEventType checks if eventType is a known event
+func IsValidEventType(eventType string) bool {
+	for _, event := range EventTypes {
+		if event == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+//GenerateSecret creates a random secret used to sign payloads
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+//Sign returns the HMAC-SHA256 signature of payload in the form sha256=<hex>
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}